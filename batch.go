@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/anti-raid/shellcli/shell"
+)
+
+// commandList is a repeatable --command flag
+type commandList []string
+
+func (c *commandList) String() string {
+	return strings.Join(*c, "; ")
+}
+
+func (c *commandList) Set(v string) error {
+	*c = append(*c, v)
+	return nil
+}
+
+// Expands the command list into individual commands
+//
+// A command of - is replaced by the newline-separated commands read from stdin (skipping blank lines and # comments)
+// and all commands are split on ';' like the shell does
+func (c commandList) Expand(stdin io.Reader) ([]string, error) {
+	var commands []string
+
+	for _, cmd := range c {
+		if cmd != "-" {
+			commands = append(commands, splitCommands(cmd)...)
+			continue
+		}
+
+		scanner := bufio.NewScanner(stdin)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			commands = append(commands, splitCommands(line)...)
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return commands, nil
+}
+
+func splitCommands(cmd string) []string {
+	var commands []string
+
+	for _, c := range strings.Split(cmd, ";") {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+
+		commands = append(commands, c)
+	}
+
+	return commands
+}
+
+// Runs commands in order, returning the exit code of the first failing command (or 0)
+//
+// If failFast is set, no further commands are run after a failure
+func runBatch(root *shell.ShellCli[cliData], commands []string, failFast bool) int {
+	exitCode := ExitOK
+
+	for _, cmd := range commands {
+		cancel, err := runCommand(root, cmd)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+
+			if exitCode == ExitOK {
+				exitCode = exitCodeFor(err)
+			}
+
+			if failFast {
+				return exitCode
+			}
+		}
+
+		if cancel {
+			fmt.Println("Exiting...")
+			return exitCode
+		}
+	}
+
+	return exitCode
+}
+
+// Runs a single command like the shell does, returning ErrUnknownCommand for commands that do not exist as the
+// shell does not export an error for them
+func runCommand(root *shell.ShellCli[cliData], cmd string) (bool, error) {
+	tokens, err := root.Splitter.Split(strings.TrimSpace(cmd))
+
+	if err == nil && len(tokens) > 0 && tokens[0] != "" && tokens[0] != "exit" && tokens[0] != "quit" {
+		if _, err := root.ParseOutCommand(tokens); err != nil {
+			return false, fmt.Errorf("%w: %s", ErrUnknownCommand, tokens[0])
+		}
+	}
+
+	return root.RunString(cmd)
+}
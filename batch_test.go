@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/shellcli/shell"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a shell whose commands record the order they are run in
+func batchShell(t *testing.T) (*shell.ShellCli[cliData], *[]string) {
+	var ran []string
+
+	command := func(name string, err error) *shell.Command[cliData] {
+		return &shell.Command[cliData]{
+			Name: name,
+			Run: func(a *shell.ShellCli[cliData], args map[string]string) error {
+				ran = append(ran, name)
+				return err
+			},
+		}
+	}
+
+	root := &shell.ShellCli[cliData]{
+		Data: &cliData{},
+		Commands: map[string]*shell.Command[cliData]{
+			"ok":      command("ok", nil),
+			"invalid": command("invalid", fmt.Errorf("%w: bad", router.ErrInvalidArgument)),
+			"denied":  command("denied", &fetch.ResponseError{Status: http.StatusForbidden}),
+		},
+	}
+
+	assert.NoError(t, root.Init())
+	return root, &ran
+}
+
+func TestCommandListExpand(t *testing.T) {
+	commands, err := commandList{"ok; denied", "-", "ok"}.Expand(strings.NewReader("# comment\ninvalid\n\nok;ok\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ok", " denied", "invalid", "ok", "ok", "ok"}, commands)
+}
+
+func TestRunBatch(t *testing.T) {
+	// Every command is run in order and the exit code is that of the first failure
+	root, ran := batchShell(t)
+	assert.Equal(t, ExitValidation, runBatch(root, []string{"ok", "invalid", "denied", "ok"}, false))
+	assert.Equal(t, []string{"ok", "invalid", "denied", "ok"}, *ran)
+
+	root, ran = batchShell(t)
+	assert.Equal(t, ExitPermission, runBatch(root, []string{"ok", "denied", "invalid"}, true))
+	assert.Equal(t, []string{"ok", "denied"}, *ran)
+
+	root, ran = batchShell(t)
+	assert.Equal(t, ExitNotFound, runBatch(root, []string{"nope", "ok"}, false))
+	assert.Equal(t, []string{"ok"}, *ran)
+
+	// exit stops the batch without failing it
+	root, ran = batchShell(t)
+	assert.Equal(t, ExitOK, runBatch(root, []string{"ok", "exit", "invalid"}, false))
+	assert.Equal(t, []string{"ok"}, *ran)
+}
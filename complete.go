@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/completion"
+	"github.com/anti-raid/shellcli/shell"
+)
+
+// Whether completions are being printed for a system shell rather than the interactive shell
+var systemShellCompletion bool
+
+// Returns the getcompletion command of the shell, with an extra format printing completions for system shells
+// (see completionsForShell). The scripts generated by the completion command call back into the binary with it
+func getCompletionCommand(root *shell.ShellCli[cliData]) *shell.Command[cliData] {
+	cmd := root.GetCompletion()
+	cmd.Args[1][1] = strings.Replace(cmd.Args[1][1], ")", "/"+completion.ShellFormat+")", 1)
+
+	run := cmd.Run
+	cmd.Run = func(a *shell.ShellCli[cliData], args map[string]string) error {
+		if args["format"] != completion.ShellFormat {
+			return run(a, args)
+		}
+
+		line := args["line"]
+
+		if line == "@empty" {
+			line = ""
+		}
+
+		systemShellCompletion = true
+		defer func() { systemShellCompletion = false }()
+
+		for _, c := range completionsForShell(a, line) {
+			fmt.Println(c)
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+// Returns whether the binary was called by a system shell completion script, in which case plugins must not be
+// run as completion has to be fast
+func isShellCompletion(args []string) bool {
+	return len(args) > 0 && args[0] == "getcompletion" && slices.Contains(args[1:], "format="+completion.ShellFormat)
+}
+
+// Returns the completions for a line in the form system shells expect: the full word being completed
+// rather than the whole line the shell's completion handler returns, followed by a tab and its description if
+// it has one
+func completionsForShell(root *shell.ShellCli[cliData], line string) []string {
+	var words []string
+
+	for _, c := range root.CompletionHandler(line) {
+		c, description := router.SplitDescription(strings.TrimSpace(c))
+
+		if c == "" {
+			continue
+		}
+
+		if idx := strings.LastIndex(c, " "); idx != -1 {
+			c = c[idx+1:]
+		}
+
+		words = append(words, router.Describe(c, strings.TrimSpace(description)))
+	}
+
+	return words
+}
+
+// Joins arguments passed by the system shell into a single command, quoting values containing spaces, quotes or
+// '=' (which would otherwise be split into another argument)
+func joinArgs(args []string) string {
+	var parts = make([]string, 0, len(args))
+
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+
+		if !ok {
+			if strings.ContainsAny(arg, " \t\"'") {
+				arg = quote(arg)
+			}
+
+			parts = append(parts, arg)
+			continue
+		}
+
+		if strings.ContainsAny(value, " \t\"'=") {
+			value = quote(value)
+		}
+
+		parts = append(parts, key+"="+value)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/shellcli/shell"
+	"github.com/stretchr/testify/assert"
+)
+
+func testShell(t *testing.T) *shell.ShellCli[cliData] {
+	root := &shell.ShellCli[cliData]{
+		Data: &cliData{},
+		Commands: map[string]*shell.Command[cliData]{
+			"greet": {
+				Name: "greet",
+				Args: [][3]string{{"name", "Who to greet", ""}, {"greeting", "The greeting", ""}},
+				Completer: func(a *shell.ShellCli[cliData], line string, args map[string]string) ([]string, error) {
+					if name, _, ok := router.TypingValue(line); ok && name == "name" {
+						return router.ValueCompletions(line, name, []string{router.Describe("alice", "An admin"), "bob"}), nil
+					}
+
+					return []string{strings.TrimSpace(line) + " name=", strings.TrimSpace(line) + " greeting="}, nil
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, root.Init())
+	return root
+}
+
+func TestCompletionsForShell(t *testing.T) {
+	root := testShell(t)
+
+	assert.Equal(t, []string{"greet"}, completionsForShell(root, ""))
+	assert.Equal(t, []string{"greet"}, completionsForShell(root, "gr"))
+
+	// Argument names keep their '=' so the scripts do not add a space after them
+	assert.Equal(t, []string{"name=", "greeting="}, completionsForShell(root, "greet "))
+
+	// Only the word being completed is returned, with its description after a tab
+	assert.Equal(t, []string{"name=alice" + router.DescriptionSep + "An admin", "name=bob"}, completionsForShell(root, "greet greeting=hi name=al"))
+	assert.Equal(t, []string{"name=alice" + router.DescriptionSep + "An admin", "name=bob"}, completionsForShell(root, "greet name="))
+}
+
+func TestIsShellCompletion(t *testing.T) {
+	assert.True(t, isShellCompletion([]string{"getcompletion", "format=shell", "line=greet "}))
+	assert.False(t, isShellCompletion([]string{"getcompletion", "line=greet "}))
+	assert.False(t, isShellCompletion([]string{"greet", "format=shell"}))
+	assert.False(t, isShellCompletion(nil))
+}
+
+func TestJoinArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"greet", "name=bob"}, "greet name=bob"},
+		{[]string{"greet", "name=bob smith"}, `greet name="bob smith"`},
+		{[]string{"greet", `name=say "hi"`}, `greet name="say \"hi\""`},
+		{[]string{"getcompletion", "format=shell", "line=greet name=b"}, `getcompletion format=shell line="greet name=b"`},
+		{[]string{"getcompletion", "line=greet "}, `getcompletion line="greet "`},
+		{[]string{"greet", "bob smith"}, `greet "bob smith"`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, joinArgs(tt.args))
+	}
+
+	// The joined command splits back into the original arguments
+	root := testShell(t)
+	tokens, err := root.Splitter.Split(joinArgs([]string{"getcompletion", "line=greet name=b "}))
+	assert.NoError(t, err)
+
+	fields, err := root.ArgSplitter.Split(tokens[1])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"line", "greet name=b "}, fields)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+// Exit codes for --command, so scripts and CI can tell failures apart
+//
+// 2 is not used as the flag package exits with it when the command line cannot be parsed
+const (
+	ExitOK         = 0
+	ExitGeneric    = 1
+	ExitAuth       = 3
+	ExitPermission = 4
+	ExitNotFound   = 5
+	ExitNetwork    = 6
+	ExitValidation = 7
+)
+
+// Returned when running a command that does not exist
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Returns the exit code for an error returned by a command
+func exitCodeFor(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var respErr *fetch.ResponseError
+	if errors.As(err, &respErr) {
+		switch {
+		case respErr.ErrorType == "permission_check" || respErr.Status == http.StatusForbidden:
+			return ExitPermission
+		case respErr.ErrorType == "settings_error" || respErr.Status == http.StatusBadRequest || respErr.Status == http.StatusUnprocessableEntity:
+			return ExitValidation
+		case respErr.Status == http.StatusUnauthorized:
+			return ExitAuth
+		case respErr.Status == http.StatusNotFound:
+			return ExitNotFound
+		}
+
+		return ExitGeneric
+	}
+
+	switch {
+	case errors.Is(err, state.ErrSessionNotFound), errors.Is(err, state.ErrSessionHasNoToken):
+		return ExitAuth
+	case errors.Is(err, router.ErrMissingArgument), errors.Is(err, router.ErrInvalidArgument), errors.Is(err, router.ErrUnknownArgument), errors.Is(err, guilds.ErrInvalidSettingsFields):
+		return ExitValidation
+	case errors.Is(err, router.ErrRouteNotFound), errors.Is(err, api.ErrTestableRouteNotFound), errors.Is(err, ErrUnknownCommand):
+		return ExitNotFound
+	case errors.Is(err, fetch.ErrServerMaintenance), errors.Is(err, context.DeadlineExceeded):
+		return ExitNetwork
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ExitNetwork
+	}
+
+	return ExitGeneric
+}
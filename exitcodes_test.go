@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"generic", errors.New("failed"), ExitGeneric},
+		{"forbidden", &fetch.ResponseError{Status: http.StatusForbidden}, ExitPermission},
+		{"permission check", &fetch.ResponseError{Status: http.StatusBadRequest, ErrorType: "permission_check"}, ExitPermission},
+		{"settings error", &fetch.ResponseError{Status: http.StatusInternalServerError, ErrorType: "settings_error"}, ExitValidation},
+		{"bad request", &fetch.ResponseError{Status: http.StatusBadRequest}, ExitValidation},
+		{"unprocessable", &fetch.ResponseError{Status: http.StatusUnprocessableEntity}, ExitValidation},
+		{"unauthorized", &fetch.ResponseError{Status: http.StatusUnauthorized}, ExitAuth},
+		{"not found", &fetch.ResponseError{Status: http.StatusNotFound}, ExitNotFound},
+		{"server error", &fetch.ResponseError{Status: http.StatusInternalServerError}, ExitGeneric},
+		{"wrapped response error", fmt.Errorf("request: %w", &fetch.ResponseError{Status: http.StatusForbidden}), ExitPermission},
+		{"no session", state.ErrSessionNotFound, ExitAuth},
+		{"invalid argument", fmt.Errorf("%w: count must be a number", router.ErrInvalidArgument), ExitValidation},
+		{"missing argument", fmt.Errorf("%w: route", router.ErrMissingArgument), ExitValidation},
+		{"invalid settings fields", fmt.Errorf("%w: channel is required", guilds.ErrInvalidSettingsFields), ExitValidation},
+		{"unknown route", router.ErrRouteNotFound, ExitNotFound},
+		{"unknown command", fmt.Errorf("%w: nope", ErrUnknownCommand), ExitNotFound},
+		{"timeout", fmt.Errorf("request: %w", context.DeadlineExceeded), ExitNetwork},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, exitCodeFor(tt.err), tt.name)
+	}
+
+	// Scripts must be able to tell validation errors from command line errors, which the flag package exits with 2
+	assert.NotEqual(t, 2, ExitValidation)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/shellcli/shell"
+)
+
+// Returns a help command that uses the routes argument spec where possible
+func helpCommand() *shell.Command[cliData] {
+	var cmd *shell.Command[cliData]
+
+	cmd = &shell.Command[cliData]{
+		Name:        "help",
+		Description: "Get help for a command",
+		Args: [][3]string{
+			{"command", "Command to get help for", ""},
+		},
+		Completer: func(a *shell.ShellCli[cliData], line string, args map[string]string) ([]string, error) {
+			partial := strings.ToLower(args["command"])
+
+			var completions []string
+			for name := range a.Commands {
+				if strings.HasPrefix(name, partial) {
+					completions = append(completions, cmd.Name+" "+name)
+				}
+			}
+
+			slices.Sort(completions)
+
+			return completions, nil
+		},
+		Run: func(a *shell.ShellCli[cliData], args map[string]string) error {
+			name, ok := args["command"]
+
+			if !ok || name == "" {
+				var names []string
+				for name := range a.Commands {
+					names = append(names, name)
+				}
+
+				slices.Sort(names)
+
+				fmt.Println("Commands:")
+
+				for _, name := range names {
+					fmt.Print("  ", name, ": ", a.Commands[name].Description, "\n")
+				}
+
+				fmt.Println("Use 'help <command>' to get help for a specific command")
+				return nil
+			}
+
+			if route := router.GetRoute(name); route != nil {
+				fmt.Print(router.Help(route))
+				return nil
+			}
+
+			c, ok := a.Commands[name]
+
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+			}
+
+			fmt.Println("Command:", name)
+			fmt.Println("Description:", c.Description)
+			fmt.Println("Arguments:")
+
+			for _, arg := range c.Args {
+				fmt.Print("  ", arg[0], ": ", arg[1], " (default: ", arg[2], ")\n")
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
 
 	_ "github.com/anti-raid/evil-befall/pkg/api_all"
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/plugins"
+	"github.com/anti-raid/evil-befall/pkg/prompt"
 	"github.com/anti-raid/evil-befall/pkg/router"
 	_ "github.com/anti-raid/evil-befall/pkg/routes"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
 	statelib "github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/shellcli/shell"
 )
@@ -33,12 +39,37 @@ func envOrString(key, fallback string) string {
 	return fallback
 }
 
+func defaultPluginsDir() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "evil-befall", "plugins")
+}
+
+func defaultPluginsCache() string {
+	dir, err := os.UserCacheDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "evil-befall", "plugins.json")
+}
+
 func main() {
 	// Create a new state
 	var mouseEnabled = envOrBool("MOUSE_ENABLED", "false") == "true"
 	var pasteEnabled = envOrBool("PASTE_ENABLED", "true") == "true"
 	var fullscreen = envOrBool("FULLSCREEN", "true") == "true"
 	var persist = envOrString("PERSIST", "evil-befall-cfg.json")
+	var pluginsDir = envOrString("PLUGINS_DIR", defaultPluginsDir())
+	var pluginsCache = envOrString("PLUGINS_CACHE", defaultPluginsCache())
+	var promptTemplate = envOrString("PROMPT_TEMPLATE", prompt.DefaultTemplate)
+
+	collections.StorePath = envOrString("COLLECTIONS", collections.StorePath)
 
 	// Set state.Prefs
 	state, err := statelib.NewState(statelib.UserPref{
@@ -59,6 +90,32 @@ func main() {
 		os.Exit(1)
 	}
 
+	prompter, err := prompt.New(promptTemplate)
+
+	if err != nil {
+		slog.Error("Failed to create prompt:", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Handle --command args, before plugins are discovered as completing for system shells must not run them
+	var commandFlags commandList
+	flag.Var(&commandFlags, "command", "Command to run. Can be passed multiple times, use - to read newline-separated commands from stdin. If unset, will run as shell")
+	failFast := flag.Bool("fail-fast", false, "Stop at the first failing command")
+	flag.Parse()
+
+	// Register external plugins as routes. Plugins are only run to describe themselves when new or changed, and
+	// never when completing for system shells
+	pluginCache := plugins.LoadDescriptionCache(pluginsCache)
+
+	for _, p := range plugins.Discover(plugins.DiscoverOptions{Dirs: []string{pluginsDir}, Cache: pluginCache, CacheOnly: isShellCompletion(flag.Args())}) {
+		if router.GetRoute(p.Command()) != nil {
+			slog.Warn("Plugin conflicts with an existing route, ignoring", slog.String("name", p.Command()), slog.String("path", p.Path))
+			continue
+		}
+
+		router.AddRoute(p)
+	}
+
 	// Create command list
 	var commands = make(map[string]*shell.Command[cliData])
 
@@ -66,15 +123,15 @@ func main() {
 		cmd := &shell.Command[cliData]{
 			Name:        route.Command(),
 			Description: route.Description(),
-			Args:        route.Arguments(),
+			Args:        router.ShellArgs(route.Arguments()),
 			Run: func(cli *shell.ShellCli[cliData], args map[string]string) error {
 				return router.Goto(route.Command(), cli.Data.State, args)
 			},
 		}
 
-		// Default completion
+		// Default completion, based on the routes argument spec
 		var completion = func(a *shell.ShellCli[cliData], line string, args map[string]string) ([]string, error) {
-			return shell.ArgBasedCompletionHandler(a, cmd, line, args)
+			return router.CompleteArgs(route, a.Data.State, line, args)
 		}
 
 		completer, ok := route.(router.CompletableRoute)
@@ -85,7 +142,16 @@ func main() {
 			}
 		}
 
-		cmd.Completer = completion
+		cmd.Completer = func(a *shell.ShellCli[cliData], line string, args map[string]string) ([]string, error) {
+			c, err := completion(a, line, args)
+
+			// System shells show descriptions themselves
+			if err != nil || systemShellCompletion {
+				return c, err
+			}
+
+			return router.InteractiveCandidates(c), nil
+		}
 
 		commands[route.Command()] = cmd
 	}
@@ -95,39 +161,41 @@ func main() {
 			State: state,
 		},
 		Prompter: func(r *shell.ShellCli[cliData]) string {
-			return "evil-befall> "
+			return prompter.Render(r.Data.State)
 		},
 		Commands:         commands,
 		HistoryPath:      "evil-befall-history.txt",
 		DebugCompletions: envOrBool("DEBUG_COMPLETIONS", "false") == "true",
 	}
 
-	root.AddCommand("help", root.Help())
-	root.AddCommand("getcompletion", root.GetCompletion())
-
-	// Handle --command args
-	command := flag.String("command", "", "Command to run. If unset, will run as shell")
-	flag.Parse()
+	root.AddCommand("help", helpCommand())
+	root.AddCommand("getcompletion", getCompletionCommand(root))
 
-	if command != nil && *command != "" {
+	if len(commandFlags) > 0 || flag.NArg() > 0 {
 		err := root.Init()
 
 		if err != nil {
-			fmt.Println("Error initializing cli: ", err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, "Error initializing cli: ", err)
+			os.Exit(ExitGeneric)
 		}
 
-		cancel, err := root.ExecuteCommands(*command)
+		// Values cannot be read from stdin with @- when it holds the commands
+		apiexec_exec.CommandsFromStdin = slices.Contains(commandFlags, "-")
+
+		commands, err := commandFlags.Expand(os.Stdin)
 
 		if err != nil {
-			fmt.Println("Error:", err)
+			fmt.Fprintln(os.Stderr, "Error reading commands:", err)
+			os.Exit(ExitGeneric)
 		}
 
-		if cancel {
-			fmt.Println("Exiting...")
+		// Any positional arguments form a single command, e.g. evil-befall apiexec.exec route=getModules. It is not
+		// split on ';' as the system shell already split the line into arguments
+		if flag.NArg() > 0 {
+			commands = append(commands, joinArgs(flag.Args()))
 		}
 
-		return
+		os.Exit(runBatch(root, commands, *failFast))
 	}
 
 	root.Run()
@@ -0,0 +1,18 @@
+// Package ansi contains the ANSI escape codes used to color terminal output
+package ansi
+
+const (
+	Reset  = "\x1b[0m"
+	Bold   = "\x1b[1m"
+	Red    = "\x1b[31m"
+	Green  = "\x1b[32m"
+	Yellow = "\x1b[33m"
+	Cyan   = "\x1b[36m"
+
+	BoldRed = "\x1b[1;31m"
+)
+
+// Wraps s in the given color
+func Color(color, s string) string {
+	return color + s + Reset
+}
@@ -3,13 +3,14 @@ package api
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"reflect"
+	"errors"
 	"strings"
 
 	"github.com/anti-raid/evil-befall/pkg/state"
 )
 
+var ErrTestableRouteNotFound = errors.New("testable route not found")
+
 type ApiRequestFuncWithOnlyResp[RespType any] func(ctx context.Context, state *state.State) (*RespType, error)
 type ApiRequestFuncWithOnlyReq[ReqType any] func(ctx context.Context, state *state.State, data ReqType) error
 type ApiRequestFuncWithReqAndResp[ReqType any, RespType any] func(ctx context.Context, state *state.State, data ReqType) (*RespType, error)
@@ -20,6 +21,47 @@ type TestableRoute interface {
 	ReqType() any
 	RespType() any
 	Exec(ctx context.Context, state *state.State) (any, error)
+	Meta() RouteMeta
+}
+
+// RouteMeta describes the HTTP request a TestableRoute makes
+type RouteMeta struct {
+	// The HTTP method of the request
+	Method string
+
+	// The path template of the request, relative to the instance URL, e.g. /guilds/{guildId}/jobs/{id}
+	Path string
+
+	// Whether the request is made with the current session
+	Auth bool
+
+	// A description of the route
+	Description string
+}
+
+// metaRoute attaches RouteMeta to a TestableRoute
+type metaRoute struct {
+	TestableRoute
+	meta RouteMeta
+}
+
+func (m *metaRoute) Meta() RouteMeta {
+	return m.meta
+}
+
+func (m *metaRoute) PopulateWithArgs(args map[string]any) (TestableRoute, error) {
+	r, err := m.TestableRoute.PopulateWithArgs(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &metaRoute{TestableRoute: r, meta: m.meta}, nil
+}
+
+// WithMeta attaches HTTP metadata to a TestableRoute
+func WithMeta(meta RouteMeta, r TestableRoute) TestableRoute {
+	return &metaRoute{TestableRoute: r, meta: meta}
 }
 
 func IsTestableRoute(r TestableRoute) {}
@@ -32,6 +74,9 @@ type TestableRouteWrapper[Data any] struct {
 	FuncRespType         func(self *TestableRouteWrapper[Data]) any
 	FuncExec             func(self *TestableRouteWrapper[Data], ctx context.Context, state *state.State) (any, error)
 	Data                 Data
+
+	// The API function wrapped by the route, e.g. guilds.GetStaffTeam
+	Func any
 }
 
 func (r *TestableRouteWrapper[Data]) ID() string {
@@ -54,8 +99,17 @@ func (r *TestableRouteWrapper[Data]) Exec(ctx context.Context, state *state.Stat
 	return r.FuncExec(r, ctx, state)
 }
 
+func (r *TestableRouteWrapper[Data]) apiFunc() any {
+	return r.Func
+}
+
+// A bare TestableRouteWrapper has no metadata, use WithMeta to attach it
+func (r *TestableRouteWrapper[Data]) Meta() RouteMeta {
+	return RouteMeta{}
+}
+
 func CreateTestableRouteWithOnlyResp[RespType any](id string, fn ApiRequestFuncWithOnlyResp[RespType]) TestableRoute {
-	trw := &TestableRouteWrapper[struct{}]{}
+	trw := &TestableRouteWrapper[struct{}]{Func: fn}
 
 	// Implement methods on trw
 	trw.FuncID = func(self *TestableRouteWrapper[struct{}]) string {
@@ -83,7 +137,7 @@ func CreateTestableRouteWithOnlyResp[RespType any](id string, fn ApiRequestFuncW
 }
 
 func CreateTestableRouteWithOnlyReq[ReqType any](id string, fn ApiRequestFuncWithOnlyReq[ReqType]) TestableRoute {
-	trw := &TestableRouteWrapper[ReqType]{}
+	trw := &TestableRouteWrapper[ReqType]{Func: fn}
 
 	// Implement methods on trw
 	trw.FuncID = func(self *TestableRouteWrapper[ReqType]) string {
@@ -114,6 +168,7 @@ func CreateTestableRouteWithOnlyReq[ReqType any](id string, fn ApiRequestFuncWit
 			FuncRespType:         self.FuncRespType,
 			FuncExec:             self.FuncExec,
 			Data:                 reqData,
+			Func:                 self.Func,
 		}, nil
 	}
 
@@ -140,7 +195,7 @@ func CreateTestableRouteWithOnlyReq[ReqType any](id string, fn ApiRequestFuncWit
 }
 
 func CreateTestableRouteWithReqAndResp[ReqType any, RespType any](id string, fn ApiRequestFuncWithReqAndResp[ReqType, RespType]) TestableRoute {
-	trw := &TestableRouteWrapper[ReqType]{}
+	trw := &TestableRouteWrapper[ReqType]{Func: fn}
 
 	// Implement methods on trw
 	trw.FuncID = func(self *TestableRouteWrapper[ReqType]) string {
@@ -171,6 +226,7 @@ func CreateTestableRouteWithReqAndResp[ReqType any, RespType any](id string, fn
 			FuncRespType:         self.FuncRespType,
 			FuncExec:             self.FuncExec,
 			Data:                 reqData,
+			Func:                 self.Func,
 		}, nil
 	}
 
@@ -196,6 +252,18 @@ func init() {
 	IsTestableRoute(&TestableRouteWrapper[struct{}]{})
 }
 
+// RouteFunc returns the API function wrapped by a route, or nil if the route does not wrap one
+func RouteFunc(r TestableRoute) any {
+	switch t := r.(type) {
+	case *metaRoute:
+		return RouteFunc(t.TestableRoute)
+	case interface{ apiFunc() any }:
+		return t.apiFunc()
+	}
+
+	return nil
+}
+
 type TestableRouteCategory struct {
 	Name   string
 	Routes []TestableRoute
@@ -232,61 +300,28 @@ func GetTestableRoutes() []TestableRoute {
 	return testableRoutes
 }
 
-// Other utilities
-func StructToQueryParamsList(s any) map[string]any {
-	// Reflect to get fields
-	refType := reflect.TypeOf(s)
-
-	switch refType.Kind() {
-	case reflect.Ptr:
-		return StructToQueryParamsList(reflect.ValueOf(s).Elem().Interface())
-	case reflect.Struct:
-		var cols = map[string]any{}
-
-		for _, f := range reflect.VisibleFields(refType) {
-			jsonTag := f.Tag.Get("json")
-			reflectOpts := f.Tag.Get("reflect")
-
-			if !strings.HasPrefix(jsonTag, "query:") || reflectOpts == "ignore" {
-				continue
-			}
-
-			// Get the value of the field
-			val := reflect.ValueOf(s).FieldByName(f.Name).Interface()
-
-			// Add to cols
-			cols[strings.Split(jsonTag, "query:")[1]] = val
+// GetTestableRoute returns the registered TestableRoute with the given ID, or nil if not found
+func GetTestableRoute(id string) TestableRoute {
+	for _, route := range GetTestableRoutes() {
+		if route.ID() == id {
+			return route
 		}
-
-		return cols
-	case reflect.Map:
-		// We just need to return the keys of the map
-		var cols = map[string]any{}
-
-		for _, key := range reflect.ValueOf(s).MapKeys() {
-			cols[key.String()] = reflect.ValueOf(s).MapIndex(key).Interface()
-		}
-
-		return cols
-	default:
-		panic("StructToQueryParamsList only accepts struct or map")
 	}
-}
 
-func QueryParamsListToString(qp map[string]any) string {
-	var parts []string
+	return nil
+}
 
-	for k, v := range qp {
-		parts = append(parts, k+"="+fmt.Sprint(v))
-	}
+// CompleteTestableRouteIDs returns the IDs of all registered TestableRoute's starting with partial
+//
+// This can be used as a completion source for route arguments
+func CompleteTestableRouteIDs(state *state.State, partial string) ([]string, error) {
+	var ids []string
 
-	if len(parts) == 0 {
-		return ""
+	for _, route := range GetTestableRoutes() {
+		if strings.HasPrefix(strings.ToLower(route.ID()), strings.ToLower(partial)) {
+			ids = append(ids, route.ID())
+		}
 	}
 
-	return "?" + strings.Join(parts, "&")
-}
-
-func StructToQueryParamsString(s any) string {
-	return QueryParamsListToString(StructToQueryParamsList(s))
+	return ids, nil
 }
@@ -4,11 +4,19 @@ import (
 	"context"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
-	"github.com/anti-raid/evil-befall/pkg/fetch"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types"
 )
 
+var (
+	createIoAuthLogin = api.RouteMeta{Method: "GET", Path: "/ioauth/login", Auth: false, Description: "Returns the URL to start an IOAuth login. No request is made"}
+	testAuth          = api.RouteMeta{Method: "POST", Path: "/auth/test", Auth: false, Description: "Tests an authorization token"}
+	createOauth2Login = api.RouteMeta{Method: "POST", Path: "/oauth2", Auth: false, Description: "Creates a session from an OAuth2 code"}
+	getUserSessions   = api.RouteMeta{Method: "GET", Path: "/sessions", Auth: true, Description: "Lists the sessions of the current user"}
+	createUserSession = api.RouteMeta{Method: "POST", Path: "/sessions", Auth: true, Description: "Creates a new (API) session for the current user"}
+	revokeUserSession = api.RouteMeta{Method: "DELETE", Path: "/sessions/{session_id}", Auth: true, Description: "Revokes a session of the current user"}
+)
+
 // CreateIoAuthLogin is special because it primarily uses query parameters
 type CreateIoAuthLoginData struct {
 	PathRedirectData string  `json:"query:path_rd"`
@@ -16,105 +24,29 @@ type CreateIoAuthLoginData struct {
 }
 
 func CreateIoAuthLogin(ctx context.Context, state *state.State, data *CreateIoAuthLoginData) (*string, error) {
-	url := state.StateFetchOptions.InstanceAPIUrl + "/ioauth/login" + api.StructToQueryParamsString(data)
-	return &url, nil
-}
-
-func TestAuth(ctx context.Context, state *state.State, data *types.TestAuth) (*types.TestAuthResponse, error) {
-	body, err := fetch.JsonBody(data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "POST",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/auth/test",
-		Body:   body,
-	})
+	url, err := api.BuildURL(state.StateFetchOptions.InstanceAPIUrl, createIoAuthLogin, data)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var res types.TestAuthResponse
-
-	if err := resp.Json(&res); err != nil {
-		return nil, err
-	}
+	return &url, nil
+}
 
-	return &res, nil
+func TestAuth(ctx context.Context, state *state.State, data *types.TestAuth) (*types.TestAuthResponse, error) {
+	return api.Do[types.TestAuthResponse](ctx, state, testAuth, data)
 }
 
 func CreateOauth2Login(ctx context.Context, state *state.State, data types.AuthorizeRequest) (*types.CreateUserSessionResponse, error) {
-	body, err := fetch.JsonBody(data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "POST",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/oauth2",
-		Body:   body,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var res types.CreateUserSessionResponse
-
-	if err := resp.Json(&res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+	return api.Do[types.CreateUserSessionResponse](ctx, state, createOauth2Login, data)
 }
 
 func GetUserSessions(ctx context.Context, state *state.State) (*types.UserSessionList, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/sessions",
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var res types.UserSessionList
-
-	if err := resp.Json(&res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+	return api.Do[types.UserSessionList](ctx, state, getUserSessions, nil)
 }
 
 func CreateUserSession(ctx context.Context, state *state.State, data *types.CreateUserSession) (*types.CreateUserSessionResponse, error) {
-	body, err := fetch.JsonBody(data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "POST",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/sessions",
-		Body:   body,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var res types.CreateUserSessionResponse
-
-	if err := resp.Json(&res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+	return api.Do[types.CreateUserSessionResponse](ctx, state, createUserSession, data)
 }
 
 type RevokeUserSessionData struct {
@@ -122,15 +54,17 @@ type RevokeUserSessionData struct {
 }
 
 func RevokeUserSession(ctx context.Context, state *state.State, data *RevokeUserSessionData) error {
-	_, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "DELETE",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/sessions/" + data.SessionID,
-	})
+	resp, err := api.Send(ctx, state, revokeUserSession, data)
 
 	if err != nil {
 		return err
 	}
 
+	// The session is still valid if the request was not sent due to dry-run mode
+	if resp.NotSent() {
+		return nil
+	}
+
 	state.Session.RemoveSessionIfExists(data.SessionID)
 
 	return nil
@@ -140,12 +74,12 @@ func init() {
 	api.RegisterTestableRouteCategory(
 		api.NewTestableRouteCategory(
 			"auth",
-			api.CreateTestableRouteWithReqAndResp("createIoAuthLogin", CreateIoAuthLogin),
-			api.CreateTestableRouteWithReqAndResp("testAuth", TestAuth),
-			api.CreateTestableRouteWithReqAndResp("createOauth2Login", CreateOauth2Login),
-			api.CreateTestableRouteWithOnlyResp("getUserSessions", GetUserSessions),
-			api.CreateTestableRouteWithReqAndResp("createUserSession", CreateUserSession),
-			api.CreateTestableRouteWithOnlyReq("revokeUserSession", RevokeUserSession),
+			api.WithMeta(createIoAuthLogin, api.CreateTestableRouteWithReqAndResp("createIoAuthLogin", CreateIoAuthLogin)),
+			api.WithMeta(testAuth, api.CreateTestableRouteWithReqAndResp("testAuth", TestAuth)),
+			api.WithMeta(createOauth2Login, api.CreateTestableRouteWithReqAndResp("createOauth2Login", CreateOauth2Login)),
+			api.WithMeta(getUserSessions, api.CreateTestableRouteWithOnlyResp("getUserSessions", GetUserSessions)),
+			api.WithMeta(createUserSession, api.CreateTestableRouteWithReqAndResp("createUserSession", CreateUserSession)),
+			api.WithMeta(revokeUserSession, api.CreateTestableRouteWithOnlyReq("revokeUserSession", RevokeUserSession)),
 		),
 	)
 }
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeUserSessionDryRun(t *testing.T) {
+	s := &state.State{}
+	s.StateFetchOptions.InstanceAPIUrl = "http://localhost:3010"
+	s.StateFetchOptions.DryRun = true
+	s.Session.UserSessions = []*types.CreateUserSessionResponse{{UserID: "1", SessionID: "s1", Token: "token", Expiry: time.Now().Add(time.Hour)}}
+
+	// The request is not sent, so the session is kept
+	assert.NoError(t, RevokeUserSession(context.Background(), s, &RevokeUserSessionData{SessionID: "s1"}))
+	assert.Len(t, s.Session.UserSessions, 1)
+}
@@ -4,56 +4,30 @@ import (
 	"context"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
-	"github.com/anti-raid/evil-befall/pkg/fetch"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types"
 	"github.com/anti-raid/evil-befall/types/silverpelt"
 )
 
-func GetApiConfig(ctx context.Context, state *state.State) (*types.ApiConfig, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/config",
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var apiConfig types.ApiConfig
-
-	if err := resp.Json(&apiConfig); err != nil {
-		return nil, err
-	}
+var (
+	getApiConfig = api.RouteMeta{Method: "GET", Path: "/config", Auth: false, Description: "Returns the API configuration of the instance"}
+	getModules   = api.RouteMeta{Method: "GET", Path: "/modules", Auth: false, Description: "Returns all modules, their commands and config options"}
+)
 
-	return &apiConfig, nil
+func GetApiConfig(ctx context.Context, state *state.State) (*types.ApiConfig, error) {
+	return api.Do[types.ApiConfig](ctx, state, getApiConfig, nil)
 }
 
 func GetModules(ctx context.Context, state *state.State) (*[]*silverpelt.CanonicalModule, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/modules",
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var moduleList []*silverpelt.CanonicalModule
-
-	if err := resp.Json(&moduleList); err != nil {
-		return nil, err
-	}
-
-	return &moduleList, nil
+	return api.Do[[]*silverpelt.CanonicalModule](ctx, state, getModules, nil)
 }
 
 func init() {
 	api.RegisterTestableRouteCategory(
 		api.NewTestableRouteCategory(
 			"core",
-			api.CreateTestableRouteWithOnlyResp("getApiConfig", GetApiConfig),
-			api.CreateTestableRouteWithOnlyResp("getModules", GetModules),
+			api.WithMeta(getApiConfig, api.CreateTestableRouteWithOnlyResp("getApiConfig", GetApiConfig)),
+			api.WithMeta(getModules, api.CreateTestableRouteWithOnlyResp("getModules", GetModules)),
 		),
 	)
 }
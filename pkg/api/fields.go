@@ -0,0 +1,139 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Where a request field is sent
+type FieldLocation string
+
+const (
+	// The field is substituted into the path template, tagged `json:"path:name"`
+	LocationPath FieldLocation = "path"
+
+	// The field is sent as a query parameter, tagged `json:"query:name"`
+	LocationQuery FieldLocation = "query"
+
+	// The field is the entire request body, tagged `json:"body"`, `json:"body:name"` or `json:"patch"`
+	LocationBody FieldLocation = "body"
+
+	// The field is a member of the JSON request body (any untagged field)
+	LocationBodyField FieldLocation = "body_field"
+)
+
+// RequestField describes a single field of a request type
+type RequestField struct {
+	// The key used for the field when populating the request (the full json tag name, e.g. path:guildId)
+	Key string
+
+	// The name of the field at its location (e.g. guildId for path:guildId)
+	Name string
+
+	Location    FieldLocation
+	Type        reflect.Type
+	Index       []int
+	Description string
+	OmitEmpty   bool
+}
+
+// Whether the field must be set. Path parameters are always required, other fields if they are not omitempty
+func (f RequestField) Required() bool {
+	if f.Location == LocationPath {
+		return true
+	}
+
+	return !f.OmitEmpty
+}
+
+// Parses a json tag into its name and whether omitempty is set
+func ParseJsonTag(tag string) (name string, omitEmpty bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty
+}
+
+// Returns the location and name of a field given its json tag name
+func FieldLocationOf(jsonName string) (FieldLocation, string) {
+	switch {
+	case strings.HasPrefix(jsonName, "path:"):
+		return LocationPath, strings.TrimPrefix(jsonName, "path:")
+	case strings.HasPrefix(jsonName, "query:"):
+		return LocationQuery, strings.TrimPrefix(jsonName, "query:")
+	case jsonName == "body" || jsonName == "patch":
+		return LocationBody, jsonName
+	case strings.HasPrefix(jsonName, "body:"):
+		return LocationBody, strings.TrimPrefix(jsonName, "body:")
+	}
+
+	return LocationBodyField, jsonName
+}
+
+// RequestFields returns the fields of a request type along with where they are sent
+//
+// Embedded structs without a json tag are flattened like encoding/json does
+func RequestFields(t reflect.Type) []RequestField {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []RequestField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+
+		if tag == "-" || f.Tag.Get("reflect") == "ignore" {
+			continue
+		}
+
+		name, omitEmpty := ParseJsonTag(tag)
+
+		if f.Anonymous && name == "" {
+			for _, inner := range RequestFields(f.Type) {
+				inner.Index = append([]int{i}, inner.Index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		loc, locName := FieldLocationOf(name)
+
+		description := f.Tag.Get("description")
+
+		if description == "" {
+			description = f.Tag.Get("comment")
+		}
+
+		fields = append(fields, RequestField{
+			Key:         name,
+			Name:        locName,
+			Location:    loc,
+			Type:        f.Type,
+			Index:       []int{i},
+			Description: description,
+			OmitEmpty:   omitEmpty,
+		})
+	}
+
+	return fields
+}
@@ -4,33 +4,27 @@ import (
 	"context"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
-	"github.com/anti-raid/evil-befall/pkg/fetch"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types"
 	"github.com/anti-raid/evil-befall/types/silverpelt"
 )
 
+var (
+	getStaffTeam                = api.RouteMeta{Method: "GET", Path: "/guilds/{guildId}/staff-team", Auth: false, Description: "Returns the staff team of a guild"}
+	getModuleConfigurations     = api.RouteMeta{Method: "GET", Path: "/guilds/{guildId}/module-configurations", Auth: true, Description: "Returns the module configurations of a guild"}
+	patchModuleConfiguration    = api.RouteMeta{Method: "PATCH", Path: "/guilds/{guildId}/module-configurations", Auth: true, Description: "Updates a module configuration of a guild"}
+	getAllCommandConfigurations = api.RouteMeta{Method: "GET", Path: "/guilds/{guildId}/command-configurations", Auth: true, Description: "Returns the command configurations of a guild"}
+	patchCommandConfiguration   = api.RouteMeta{Method: "PATCH", Path: "/guilds/{guildId}/command-configurations", Auth: true, Description: "Updates a command configuration of a guild"}
+	settingsExecute             = api.RouteMeta{Method: "POST", Path: "/guilds/{guildId}/settings", Auth: true, Description: "Executes a settings operation"}
+	settingsGetSuggestions      = api.RouteMeta{Method: "POST", Path: "/guilds/{guildId}/settings/suggestions", Auth: true, Description: "Returns suggestions for the value of a setting column"}
+)
+
 type GetStaffTeamData struct {
 	GuildID string `json:"path:guildId"`
 }
 
 func GetStaffTeam(ctx context.Context, state *state.State, data *GetStaffTeamData) (*types.GuildStaffTeam, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/staff-team",
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var staffTeam types.GuildStaffTeam
-
-	if err := resp.Json(&staffTeam); err != nil {
-		return nil, err
-	}
-
-	return &staffTeam, nil
+	return api.Do[types.GuildStaffTeam](ctx, state, getStaffTeam, data)
 }
 
 type GetModuleConfigurationsData struct {
@@ -38,22 +32,7 @@ type GetModuleConfigurationsData struct {
 }
 
 func GetModuleConfigurations(ctx context.Context, state *state.State, data *GetModuleConfigurationsData) (*[]*silverpelt.GuildModuleConfiguration, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/module-configurations",
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var gmc []*silverpelt.GuildModuleConfiguration
-
-	if err := resp.Json(&gmc); err != nil {
-		return nil, err
-	}
-
-	return &gmc, nil
+	return api.Do[[]*silverpelt.GuildModuleConfiguration](ctx, state, getModuleConfigurations, data)
 }
 
 type PatchModuleConfigurationsData struct {
@@ -62,29 +41,7 @@ type PatchModuleConfigurationsData struct {
 }
 
 func PatchModuleConfiguration(ctx context.Context, state *state.State, data *PatchModuleConfigurationsData) (*silverpelt.GuildModuleConfiguration, error) {
-	body, err := fetch.JsonBody(data.PatchGuildModuleConfiguration)
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "PATCH",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/module-configurations",
-		Body:   body,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var gmc silverpelt.GuildModuleConfiguration
-
-	if err := resp.Json(&gmc); err != nil {
-		return nil, err
-	}
-
-	return &gmc, nil
+	return api.Do[silverpelt.GuildModuleConfiguration](ctx, state, patchModuleConfiguration, data)
 }
 
 type GetAllCommandConfigurationsData struct {
@@ -92,22 +49,7 @@ type GetAllCommandConfigurationsData struct {
 }
 
 func GetAllCommandConfigurations(ctx context.Context, state *state.State, data *GetAllCommandConfigurationsData) (*[]*silverpelt.FullGuildCommandConfiguration, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/command-configurations",
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var gcc []*silverpelt.FullGuildCommandConfiguration
-
-	if err := resp.Json(&gcc); err != nil {
-		return nil, err
-	}
-
-	return &gcc, nil
+	return api.Do[[]*silverpelt.FullGuildCommandConfiguration](ctx, state, getAllCommandConfigurations, data)
 }
 
 type PatchCommandConfigurationsData struct {
@@ -116,29 +58,7 @@ type PatchCommandConfigurationsData struct {
 }
 
 func PatchCommandConfiguration(ctx context.Context, state *state.State, data *PatchCommandConfigurationsData) (*silverpelt.FullGuildCommandConfiguration, error) {
-	body, err := fetch.JsonBody(data.PatchGuildCommandConfiguration)
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "PATCH",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/command-configurations",
-		Body:   body,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var gcc silverpelt.FullGuildCommandConfiguration
-
-	if err := resp.Json(&gcc); err != nil {
-		return nil, err
-	}
-
-	return &gcc, nil
+	return api.Do[silverpelt.FullGuildCommandConfiguration](ctx, state, patchCommandConfiguration, data)
 }
 
 type SettingsExecuteData struct {
@@ -146,42 +66,37 @@ type SettingsExecuteData struct {
 	GuildID             string                 `json:"path:guildId"`
 }
 
+// SettingsExecute validates the fields of a settings operation before sending it, unless ctx is from WithoutValidation
 func SettingsExecute(ctx context.Context, state *state.State, data *SettingsExecuteData) (*types.SettingsExecuteResponse, error) {
-	body, err := fetch.JsonBody(data.SettingsExecuteData)
-
-	if err != nil {
-		return nil, err
+	if !skipValidation(ctx) {
+		if err := validateSettingsExecute(ctx, state, data.SettingsExecuteData); err != nil {
+			return nil, err
+		}
 	}
 
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "POST",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/settings",
-		Body:   body,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var ser types.SettingsExecuteResponse
+	return api.Do[types.SettingsExecuteResponse](ctx, state, settingsExecute, data)
+}
 
-	if err := resp.Json(&ser); err != nil {
-		return nil, err
-	}
+type SettingsGetSuggestionsData struct {
+	SettingsGetSuggestionsData *types.SettingsGetSuggestions `json:"body"`
+	GuildID                    string                        `json:"path:guildId"`
+}
 
-	return &ser, nil
+func SettingsGetSuggestions(ctx context.Context, state *state.State, data *SettingsGetSuggestionsData) (*types.SettingsGetSuggestionsResponse, error) {
+	return api.Do[types.SettingsGetSuggestionsResponse](ctx, state, settingsGetSuggestions, data)
 }
 
 func init() {
 	api.RegisterTestableRouteCategory(
 		api.NewTestableRouteCategory(
 			"guilds",
-			api.CreateTestableRouteWithReqAndResp("getStaffTeam", GetStaffTeam),
-			api.CreateTestableRouteWithReqAndResp("getModuleConfigurations", GetModuleConfigurations),
-			api.CreateTestableRouteWithReqAndResp("patchModuleConfiguration", PatchModuleConfiguration),
-			api.CreateTestableRouteWithReqAndResp("getAllCommandConfigurations", GetAllCommandConfigurations),
-			api.CreateTestableRouteWithReqAndResp("patchCommandConfiguration", PatchCommandConfiguration),
-			api.CreateTestableRouteWithReqAndResp("settingsExecute", SettingsExecute),
+			api.WithMeta(getStaffTeam, api.CreateTestableRouteWithReqAndResp("getStaffTeam", GetStaffTeam)),
+			api.WithMeta(getModuleConfigurations, api.CreateTestableRouteWithReqAndResp("getModuleConfigurations", GetModuleConfigurations)),
+			api.WithMeta(patchModuleConfiguration, api.CreateTestableRouteWithReqAndResp("patchModuleConfiguration", PatchModuleConfiguration)),
+			api.WithMeta(getAllCommandConfigurations, api.CreateTestableRouteWithReqAndResp("getAllCommandConfigurations", GetAllCommandConfigurations)),
+			api.WithMeta(patchCommandConfiguration, api.CreateTestableRouteWithReqAndResp("patchCommandConfiguration", PatchCommandConfiguration)),
+			api.WithMeta(settingsExecute, api.CreateTestableRouteWithReqAndResp("settingsExecute", SettingsExecute)),
+			api.WithMeta(settingsGetSuggestions, api.CreateTestableRouteWithReqAndResp("settingsGetSuggestions", SettingsGetSuggestions)),
 		),
 	)
 }
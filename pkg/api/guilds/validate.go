@@ -0,0 +1,95 @@
+package guilds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/anti-raid/evil-befall/pkg/api/core"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+)
+
+// Returned by SettingsExecute for operations whose fields are rejected without sending them
+var ErrInvalidSettingsFields = errors.New("invalid settings fields")
+
+type noValidationKey struct{}
+
+type modulesCacheKey struct{}
+
+// The modules of each instance, reused while validating the operations of a single command
+type modulesCache struct {
+	mu      sync.Mutex
+	modules map[string]*[]*silverpelt.CanonicalModule
+}
+
+// Returns a context in which SettingsExecute sends operations as given, e.g. to check that the server rejects
+// invalid fields itself
+func WithoutValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noValidationKey{}, true)
+}
+
+func skipValidation(ctx context.Context) bool {
+	skip, _ := ctx.Value(noValidationKey{}).(bool)
+	return skip
+}
+
+// Returns a context in which the modules fetched to validate settings operations are reused, for commands
+// sending many operations
+func WithModulesCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, modulesCacheKey{}, &modulesCache{modules: map[string]*[]*silverpelt.CanonicalModule{}})
+}
+
+func getModules(ctx context.Context, state *state.State) (*[]*silverpelt.CanonicalModule, error) {
+	cache, _ := ctx.Value(modulesCacheKey{}).(*modulesCache)
+
+	if cache == nil {
+		return core.GetModules(ctx, state)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if modules, ok := cache.modules[state.StateFetchOptions.InstanceAPIUrl]; ok {
+		return modules, nil
+	}
+
+	modules, err := core.GetModules(ctx, state)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cache.modules[state.StateFetchOptions.InstanceAPIUrl] = modules
+
+	return modules, nil
+}
+
+// Validates the fields of a settings operation against the config option of its setting
+func validateSettingsExecute(ctx context.Context, state *state.State, data *types.SettingsExecute) error {
+	if data == nil {
+		return nil
+	}
+
+	// The modules request is not part of the operation, so is not recorded or intercepted with it
+	modules, err := getModules(fetch.WithInterceptor(fetch.WithRecorder(ctx, nil), nil), state)
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch modules to validate the fields: %w", err)
+	}
+
+	opt, err := silverpelt.FindConfigOption(*modules, data.Module, data.Setting)
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSettingsFields, err)
+	}
+
+	if se := opt.ValidateFields(data.Operation, &data.Fields); se != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSettingsFields, fetch.NewSettingsErrorFormatter(*se).ToMarkdown())
+	}
+
+	return nil
+}
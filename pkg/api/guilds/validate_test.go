@@ -0,0 +1,74 @@
+package guilds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const testModules = `[{"id": "logging", "config_options": [{"id": "sinks", "primary_key": "id", "columns": [
+	{"id": "id", "column_type": {"Scalar": {"column_type": {"Uuid": {}}}}, "ignored_for": ["Create"]},
+	{"id": "channel", "column_type": {"Scalar": {"column_type": {"String": {}}}}}
+]}]}]`
+
+func TestSettingsExecuteValidation(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		if r.URL.Path == "/modules" {
+			w.Write([]byte(testModules))
+			return
+		}
+
+		w.Write([]byte(`{"fields": []}`))
+	}))
+	defer srv.Close()
+
+	s := &state.State{}
+	s.StateFetchOptions.InstanceAPIUrl = srv.URL
+	s.Session.UserSessions = []*types.CreateUserSessionResponse{{UserID: "1", Token: "token", Expiry: time.Now().Add(time.Hour)}}
+
+	data := &SettingsExecuteData{
+		GuildID:             "1",
+		SettingsExecuteData: &types.SettingsExecute{Operation: silverpelt.Create, Module: "logging", Setting: "sinks", Fields: *orderedmap.New[string, any]()},
+	}
+
+	// Invalid fields are rejected without sending the operation, and the modules request is not recorded
+	rec := &fetch.Recorder{}
+	_, err := SettingsExecute(fetch.WithRecorder(context.Background(), rec), s, data)
+	assert.ErrorIs(t, err, ErrInvalidSettingsFields)
+	assert.ErrorContains(t, err, "channel")
+	assert.Equal(t, []string{"/modules"}, paths)
+	assert.Empty(t, rec.Exchanges())
+
+	_, err = SettingsExecute(WithoutValidation(context.Background()), s, data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/modules", "/guilds/1/settings"}, paths)
+
+	// Modules are only fetched once with a cache
+	paths = nil
+	data.SettingsExecuteData.Fields.Set("channel", "123")
+
+	ctx := WithModulesCache(context.Background())
+	for range 2 {
+		_, err = SettingsExecute(ctx, s, data)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"/modules", "/guilds/1/settings", "/guilds/1/settings"}, paths)
+}
@@ -4,33 +4,24 @@ import (
 	"context"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
-	"github.com/anti-raid/evil-befall/pkg/fetch"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types"
 )
 
+var (
+	getGuildJob           = api.RouteMeta{Method: "GET", Path: "/guilds/{guildId}/jobs/{id}", Auth: true, Description: "Returns a job of a guild"}
+	getJobList            = api.RouteMeta{Method: "GET", Path: "/guilds/{guildId}/jobs", Auth: true, Description: "Lists the jobs of a guild"}
+	createGuildJob        = api.RouteMeta{Method: "POST", Path: "/guilds/{guildId}/jobs/{name}", Auth: true, Description: "Creates a job in a guild"}
+	getIOAuthDownloadLink = api.RouteMeta{Method: "GET", Path: "/jobs/{id}/ioauth/download-link", Auth: false, Description: "Returns the download link of a jobs output. No request is made"}
+)
+
 type GetGuildJobData struct {
 	GuildID string `json:"path:guildId"`
 	JobID   string `json:"path:id"`
 }
 
 func GetGuildJob(ctx context.Context, state *state.State, data *GetGuildJobData) (*types.Job, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/jobs/" + data.JobID,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var job types.Job
-
-	if err := resp.Json(&job); err != nil {
-		return nil, err
-	}
-
-	return &job, nil
+	return api.Do[types.Job](ctx, state, getGuildJob, data)
 }
 
 type GetJobListData struct {
@@ -40,22 +31,7 @@ type GetJobListData struct {
 }
 
 func GetJobList(ctx context.Context, state *state.State, data *GetJobListData) (*types.JobListResponse, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/jobs" + api.StructToQueryParamsString(data),
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var jobList types.JobListResponse
-
-	if err := resp.Json(&jobList); err != nil {
-		return nil, err
-	}
-
-	return &jobList, nil
+	return api.Do[types.JobListResponse](ctx, state, getJobList, data)
 }
 
 type CreateGuildJobData struct {
@@ -65,29 +41,7 @@ type CreateGuildJobData struct {
 }
 
 func CreateGuildJob(ctx context.Context, state *state.State, data *CreateGuildJobData) (*types.JobCreateResponse, error) {
-	body, err := fetch.JsonBody(data.Data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "POST",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/guilds/" + data.GuildID + "/jobs/" + data.Name,
-		Body:   body,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var jcr types.JobCreateResponse
-
-	if err := resp.Json(&jcr); err != nil {
-		return nil, err
-	}
-
-	return &jcr, nil
+	return api.Do[types.JobCreateResponse](ctx, state, createGuildJob, data)
 }
 
 // GetIOAuthDownloadLinkData needs to return a struct as it is special
@@ -97,7 +51,12 @@ type GetIOAuthDownloadLinkData struct {
 }
 
 func GetIOAuthDownloadLink(ctx context.Context, state *state.State, data *GetIOAuthDownloadLinkData) (*string, error) {
-	url := state.StateFetchOptions.InstanceAPIUrl + "/jobs/" + data.ID + "/ioauth/download-link" + api.StructToQueryParamsString(data)
+	url, err := api.BuildURL(state.StateFetchOptions.InstanceAPIUrl, getIOAuthDownloadLink, data)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return &url, nil
 }
 
@@ -105,10 +64,10 @@ func init() {
 	api.RegisterTestableRouteCategory(
 		api.NewTestableRouteCategory(
 			"jobs",
-			api.CreateTestableRouteWithReqAndResp("getGuildJob", GetGuildJob),
-			api.CreateTestableRouteWithReqAndResp("getJobList", GetJobList),
-			api.CreateTestableRouteWithReqAndResp("createGuildJob", CreateGuildJob),
-			api.CreateTestableRouteWithReqAndResp("getIOAuthDownloadLink", GetIOAuthDownloadLink),
+			api.WithMeta(getGuildJob, api.CreateTestableRouteWithReqAndResp("getGuildJob", GetGuildJob)),
+			api.WithMeta(getJobList, api.CreateTestableRouteWithReqAndResp("getJobList", GetJobList)),
+			api.WithMeta(createGuildJob, api.CreateTestableRouteWithReqAndResp("createGuildJob", CreateGuildJob)),
+			api.WithMeta(getIOAuthDownloadLink, api.CreateTestableRouteWithReqAndResp("getIOAuthDownloadLink", GetIOAuthDownloadLink)),
 		),
 	)
 }
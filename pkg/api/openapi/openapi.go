@@ -0,0 +1,464 @@
+// Package openapi generates an OpenAPI 3.1 document from the TestableRoute registry
+//
+// The document is derived purely from the Go types (struct tags and descriptions) and RouteMeta, so it describes
+// what evil-befall believes the Anti-Raid API looks like
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/bigint"
+)
+
+const Version = "3.1.0"
+
+// The name of the security scheme used for authorized routes
+const SecuritySchemeName = "UserToken"
+
+type Schema = map[string]any
+
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Servers    []Server                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas         map[string]Schema `json:"schemas"`
+	SecuritySchemes map[string]Schema `json:"securitySchemes"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	Schema      Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+var (
+	typeParamPkgRegex = regexp.MustCompile(`[\w.\-]+/`)
+	invalidNameRegex  = regexp.MustCompile(`[^A-Za-z0-9._\-]`)
+	pathParamRegex    = regexp.MustCompile(`\{([^}]+)\}`)
+
+	timeType     = reflect.TypeOf(time.Time{})
+	bigintType   = reflect.TypeOf(bigint.BigInt{})
+	rawJsonType  = reflect.TypeOf(json.RawMessage{})
+	enumListType = reflect.TypeOf((*interface{ List() []string })(nil)).Elem()
+)
+
+const orderedMapPkg = "github.com/wk8/go-ordered-map/v2"
+
+// Generator builds schemas, collecting named structs into components
+type Generator struct {
+	Schemas map[string]Schema
+}
+
+func NewGenerator() *Generator {
+	return &Generator{Schemas: map[string]Schema{}}
+}
+
+// Returns the component name of a named type, e.g. types.Clearable_bool_
+func ComponentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	name := typeParamPkgRegex.ReplaceAllString(t.Name(), "")
+
+	if idx := strings.LastIndex(pkg, "/"); idx != -1 {
+		pkg = pkg[idx+1:]
+	}
+
+	if pkg != "" {
+		name = pkg + "." + name
+	}
+
+	return invalidNameRegex.ReplaceAllString(name, "_")
+}
+
+// Returns the schema for a type. Named structs are added to the components and referenced
+func (g *Generator) Schema(t reflect.Type) Schema {
+	if t == nil {
+		return Schema{}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return nullable(g.Schema(t.Elem()))
+	}
+
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t == bigintType:
+		return Schema{"type": "string", "format": "bigint"}
+	case t == rawJsonType:
+		return Schema{}
+	case t.PkgPath() == orderedMapPkg:
+		// Ordered maps marshal to an object, the value type can be found from Get
+		if get, ok := reflect.PointerTo(t).MethodByName("Get"); ok && get.Type.NumOut() > 0 {
+			return Schema{"type": "object", "additionalProperties": g.Schema(get.Type.Out(0))}
+		}
+
+		return Schema{"type": "object"}
+	}
+
+	var s Schema
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s = Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = Schema{"type": "integer"}
+
+		if t.Kind() == reflect.Int64 {
+			s["format"] = "int64"
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		s = Schema{"type": "number"}
+	case reflect.String:
+		s = Schema{"type": "string"}
+	case reflect.Interface:
+		return Schema{}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.Schema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+
+		return Schema{"type": "array", "items": g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		name := ComponentName(t)
+
+		if _, ok := g.Schemas[name]; !ok {
+			// Register first so recursive types terminate
+			g.Schemas[name] = Schema{}
+			g.Schemas[name] = g.structSchema(t)
+		}
+
+		return Schema{"$ref": "#/components/schemas/" + name}
+	default:
+		return Schema{}
+	}
+
+	if t.Implements(enumListType) {
+		s["enum"] = reflect.Zero(t).Interface().(interface{ List() []string }).List()
+	}
+
+	return s
+}
+
+func nullable(s Schema) Schema {
+	if typ, ok := s["type"].(string); ok {
+		ns := Schema{}
+
+		for k, v := range s {
+			ns[k] = v
+		}
+
+		ns["type"] = []string{typ, "null"}
+		return ns
+	}
+
+	if len(s) == 0 {
+		return s
+	}
+
+	return Schema{"anyOf": []Schema{s, {"type": "null"}}}
+}
+
+// A JSON property of a struct
+type Property struct {
+	Name        string
+	Type        reflect.Type
+	Description string
+	Required    bool
+}
+
+// Returns the JSON properties of a struct, flattening embedded structs like encoding/json
+func Properties(t reflect.Type) []Property {
+	var props []Property
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, omitEmpty := api.ParseJsonTag(tag)
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				props = append(props, Properties(ft)...)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		description := f.Tag.Get("description")
+
+		if description == "" {
+			description = f.Tag.Get("comment")
+		}
+
+		props = append(props, Property{
+			Name:        name,
+			Type:        f.Type,
+			Description: description,
+			Required:    !omitEmpty,
+		})
+	}
+
+	return props
+}
+
+func (g *Generator) structSchema(t reflect.Type) Schema {
+	return g.propertiesSchema(Properties(t))
+}
+
+func (g *Generator) propertiesSchema(props []Property) Schema {
+	properties := map[string]Schema{}
+	required := []string{}
+
+	for _, p := range props {
+		ps := g.Schema(p.Type)
+
+		if p.Description != "" {
+			ps = withDescription(ps, p.Description)
+		}
+
+		properties[p.Name] = ps
+
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+
+	s := Schema{"type": "object", "properties": properties}
+
+	if len(required) > 0 {
+		s["required"] = required
+	}
+
+	return s
+}
+
+func withDescription(s Schema, description string) Schema {
+	ns := Schema{}
+
+	for k, v := range s {
+		ns[k] = v
+	}
+
+	ns["description"] = description
+
+	return ns
+}
+
+// Returns whether a method can have a request body
+func hasBody(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete
+}
+
+// Builds the operation for a route
+func (g *Generator) Operation(category string, route api.TestableRoute) *Operation {
+	meta := route.Meta()
+
+	op := &Operation{
+		OperationID: route.ID(),
+		Summary:     meta.Description,
+		Tags:        []string{category},
+		Responses:   map[string]Response{},
+	}
+
+	if meta.Auth {
+		op.Security = []map[string][]string{{SecuritySchemeName: {}}}
+	}
+
+	var bodyProps []Property
+	var seenPathParams []string
+
+	for _, f := range api.RequestFields(reflect.TypeOf(route.ReqType())) {
+		switch f.Location {
+		case api.LocationPath, api.LocationQuery:
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        f.Name,
+				In:          string(f.Location),
+				Description: f.Description,
+				Required:    f.Required(),
+				Schema:      g.Schema(f.Type),
+			})
+
+			if f.Location == api.LocationPath {
+				seenPathParams = append(seenPathParams, f.Name)
+			}
+		case api.LocationBody:
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: g.Schema(f.Type)}},
+			}
+		case api.LocationBodyField:
+			bodyProps = append(bodyProps, Property{Name: f.Name, Type: f.Type, Description: f.Description, Required: f.Required()})
+		}
+	}
+
+	// Path parameters in the template that are not in the request type
+	for _, m := range pathParamRegex.FindAllStringSubmatch(meta.Path, -1) {
+		if !slices.Contains(seenPathParams, m[1]) {
+			op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: Schema{"type": "string"}})
+		}
+	}
+
+	if op.RequestBody == nil && len(bodyProps) > 0 && hasBody(meta.Method) {
+		var schema Schema
+
+		reqType := reflect.TypeOf(route.ReqType())
+		for reqType.Kind() == reflect.Ptr {
+			reqType = reqType.Elem()
+		}
+
+		// If the whole request type is the body, reference it directly
+		if reqType.Name() != "" && len(bodyProps) == len(api.RequestFields(reqType)) {
+			schema = g.Schema(reqType)
+		} else {
+			schema = g.propertiesSchema(bodyProps)
+		}
+
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: schema}},
+		}
+	}
+
+	respType := reflect.TypeOf(route.RespType())
+
+	if respType == nil || (respType.Kind() == reflect.Struct && respType.NumField() == 0) {
+		op.Responses["204"] = Response{Description: "No content"}
+	} else {
+		op.Responses["200"] = Response{
+			Description: "Success",
+			Content:     map[string]MediaType{"application/json": {Schema: g.Schema(respType)}},
+		}
+	}
+
+	op.Responses["default"] = Response{
+		Description: "Error. The X-Error-Type header describes the kind of error",
+		Content:     map[string]MediaType{"application/json": {Schema: g.Schema(reflect.TypeOf(types.ApiError{}))}},
+	}
+
+	return op
+}
+
+// Generate builds an OpenAPI document for the given categories
+//
+// Routes without RouteMeta are skipped
+func Generate(categories []api.TestableRouteCategory, serverUrl string) *Document {
+	g := NewGenerator()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Anti-Raid API (as seen by evil-befall)",
+			Description: "Generated from the evil-befall TestableRoute registry",
+			Version:     "evil-befall",
+		},
+		Paths: map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: g.Schemas,
+			SecuritySchemes: map[string]Schema{
+				SecuritySchemeName: {
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "A session token in the form `User <token>`",
+				},
+			},
+		},
+	}
+
+	if serverUrl != "" {
+		doc.Servers = []Server{{URL: serverUrl}}
+	}
+
+	for _, cat := range categories {
+		for _, route := range cat.Routes {
+			meta := route.Meta()
+
+			if meta.Path == "" || meta.Method == "" {
+				continue
+			}
+
+			if doc.Paths[meta.Path] == nil {
+				doc.Paths[meta.Path] = map[string]*Operation{}
+			}
+
+			doc.Paths[meta.Path][strings.ToLower(meta.Method)] = g.Operation(cat.Name, route)
+		}
+	}
+
+	return doc
+}
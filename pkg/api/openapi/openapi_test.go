@@ -0,0 +1,170 @@
+package openapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+type testNode struct {
+	Name     string      `json:"name" description:"The name of the node"`
+	Parent   *testNode   `json:"parent,omitempty"`
+	Children []*testNode `json:"children"`
+}
+
+type testRequest struct {
+	GuildID string `json:"path:guildId"`
+	Limit   *int   `json:"query:limit,omitempty" description:"How many to return"`
+	Name    string `json:"name"`
+	Note    string `json:"note,omitempty"`
+}
+
+type testBody struct {
+	Name string `json:"name"`
+}
+
+type testWholeBody struct {
+	GuildID string    `json:"path:guildId"`
+	Body    *testBody `json:"body:data"`
+}
+
+type testRoute struct {
+	meta api.RouteMeta
+	req  any
+	resp any
+}
+
+func (r *testRoute) ID() string { return "testRoute" }
+
+func (r *testRoute) PopulateWithArgs(args map[string]any) (api.TestableRoute, error) { return r, nil }
+
+func (r *testRoute) ReqType() any { return r.req }
+
+func (r *testRoute) RespType() any { return r.resp }
+
+func (r *testRoute) Exec(ctx context.Context, state *state.State) (any, error) { return nil, nil }
+
+func (r *testRoute) Meta() api.RouteMeta { return r.meta }
+
+func TestComponentName(t *testing.T) {
+	assert.Equal(t, "openapi.testNode", ComponentName(reflect.TypeOf(testNode{})))
+	assert.Equal(t, "types.ApiError", ComponentName(reflect.TypeOf(types.ApiError{})))
+	assert.Equal(t, "types.Clearable_bool_", ComponentName(reflect.TypeOf(types.Clearable[bool]{})))
+	assert.Equal(t, "types.Clearable_openapi.testBody_", ComponentName(reflect.TypeOf(types.Clearable[testBody]{})))
+}
+
+func TestSchema(t *testing.T) {
+	g := NewGenerator()
+
+	assert.Equal(t, Schema{"type": "string", "format": "date-time"}, g.Schema(reflect.TypeOf(time.Time{})))
+	assert.Equal(t, Schema{"type": []string{"string", "null"}, "format": "date-time"}, g.Schema(reflect.TypeOf(&time.Time{})))
+	assert.Equal(t, Schema{"type": "integer", "format": "int64"}, g.Schema(reflect.TypeOf(int64(0))))
+	assert.Equal(t, Schema{"type": "string", "format": "byte"}, g.Schema(reflect.TypeOf([]byte{})))
+	assert.Equal(t, Schema{}, g.Schema(reflect.TypeOf((*any)(nil)).Elem()))
+
+	assert.Equal(t, Schema{"type": "object", "additionalProperties": Schema{"type": "integer"}}, g.Schema(reflect.TypeOf(orderedmap.OrderedMap[string, int]{})))
+	assert.Equal(t, Schema{"type": "object", "additionalProperties": Schema{"type": "boolean"}}, g.Schema(reflect.TypeOf(map[string]bool{})))
+}
+
+func TestSchemaStructs(t *testing.T) {
+	g := NewGenerator()
+
+	// Named structs are referenced, and recursive types terminate
+	ref := Schema{"$ref": "#/components/schemas/openapi.testNode"}
+	assert.Equal(t, ref, g.Schema(reflect.TypeOf(testNode{})))
+	assert.Equal(t, Schema{"anyOf": []Schema{ref, {"type": "null"}}}, g.Schema(reflect.TypeOf(&testNode{})))
+	assert.Len(t, g.Schemas, 1)
+
+	// omitempty fields are optional
+	assert.Equal(t, Schema{
+		"type": "object",
+		"properties": map[string]Schema{
+			"name":     {"type": "string", "description": "The name of the node"},
+			"parent":   {"anyOf": []Schema{ref, {"type": "null"}}},
+			"children": {"type": "array", "items": Schema{"anyOf": []Schema{ref, {"type": "null"}}}},
+		},
+		"required": []string{"name", "children"},
+	}, g.Schemas["openapi.testNode"])
+
+	// Anonymous structs are inlined
+	assert.Equal(t, Schema{
+		"type":       "object",
+		"properties": map[string]Schema{"a": {"type": "boolean"}},
+		"required":   []string{"a"},
+	}, g.Schema(reflect.TypeOf(struct {
+		A bool `json:"a"`
+	}{})))
+}
+
+func TestOperation(t *testing.T) {
+	g := NewGenerator()
+
+	op := g.Operation("tests", &testRoute{
+		meta: api.RouteMeta{Method: "POST", Path: "/guilds/{guildId}/things/{thingId}", Auth: true, Description: "Make a thing"},
+		req:  &testRequest{},
+		resp: testBody{},
+	})
+
+	assert.Equal(t, "testRoute", op.OperationID)
+	assert.Equal(t, "Make a thing", op.Summary)
+	assert.Equal(t, []map[string][]string{{SecuritySchemeName: {}}}, op.Security)
+
+	// Path and query fields are parameters, path parameters only in the template are strings
+	assert.Equal(t, []Parameter{
+		{Name: "guildId", In: "path", Required: true, Schema: Schema{"type": "string"}},
+		{Name: "limit", In: "query", Description: "How many to return", Schema: Schema{"type": []string{"integer", "null"}}},
+		{Name: "thingId", In: "path", Required: true, Schema: Schema{"type": "string"}},
+	}, op.Parameters)
+
+	// Other fields make up the body
+	assert.Equal(t, Schema{
+		"type":       "object",
+		"properties": map[string]Schema{"name": {"type": "string"}, "note": {"type": "string"}},
+		"required":   []string{"name"},
+	}, op.RequestBody.Content["application/json"].Schema)
+
+	assert.Equal(t, Schema{"$ref": "#/components/schemas/openapi.testBody"}, op.Responses["200"].Content["application/json"].Schema)
+	assert.Equal(t, Schema{"$ref": "#/components/schemas/types.ApiError"}, op.Responses["default"].Content["application/json"].Schema)
+
+	// A body: field is the whole body
+	op = g.Operation("tests", &testRoute{
+		meta: api.RouteMeta{Method: "PATCH", Path: "/guilds/{guildId}"},
+		req:  &testWholeBody{},
+		resp: struct{}{},
+	})
+
+	assert.Nil(t, op.Security)
+	assert.Equal(t, Schema{"anyOf": []Schema{{"$ref": "#/components/schemas/openapi.testBody"}, {"type": "null"}}}, op.RequestBody.Content["application/json"].Schema)
+	assert.Contains(t, op.Responses, "204")
+
+	// GET requests have no body
+	op = g.Operation("tests", &testRoute{
+		meta: api.RouteMeta{Method: "GET", Path: "/guilds/{guildId}/things/{thingId}"},
+		req:  &testRequest{},
+	})
+
+	assert.Nil(t, op.RequestBody)
+	assert.Contains(t, op.Responses, "204")
+}
+
+func TestGenerate(t *testing.T) {
+	route := &testRoute{meta: api.RouteMeta{Method: "GET", Path: "/things"}, req: &struct{}{}, resp: testBody{}}
+
+	doc := Generate([]api.TestableRouteCategory{{Name: "tests", Routes: []api.TestableRoute{route, &testRoute{}}}}, "https://example.com")
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, []Server{{URL: "https://example.com"}}, doc.Servers)
+
+	// Routes without metadata are skipped
+	assert.Len(t, doc.Paths, 1)
+	assert.Equal(t, "testRoute", doc.Paths["/things"]["get"].OperationID)
+	assert.Contains(t, doc.Components.Schemas, "openapi.testBody")
+	assert.Contains(t, doc.Components.Schemas, "types.ApiError")
+}
@@ -4,33 +4,22 @@ import (
 	"context"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
-	"github.com/anti-raid/evil-befall/pkg/fetch"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types/dovetypes"
 )
 
+var (
+	getPlatformUser        = api.RouteMeta{Method: "GET", Path: "/platform/user/{id}", Auth: false, Description: "Returns a user on a platform"}
+	clearPlatformUserCache = api.RouteMeta{Method: "DELETE", Path: "/platform/user/{id}", Auth: false, Description: "Clears the cache of a platform user"}
+)
+
 type GetPlatformUserData struct {
 	ID       string `json:"path:id"`
 	Platform string `json:"query:platform"`
 }
 
 func GetPlatformUser(ctx context.Context, state *state.State, data *GetPlatformUserData) (*dovetypes.PlatformUser, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/platform/user/" + data.ID + api.StructToQueryParamsString(data),
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var platformUser dovetypes.PlatformUser
-
-	if err := resp.Json(&platformUser); err != nil {
-		return nil, err
-	}
-
-	return &platformUser, nil
+	return api.Do[dovetypes.PlatformUser](ctx, state, getPlatformUser, data)
 }
 
 type ClearPlatformUserCacheData struct {
@@ -39,24 +28,15 @@ type ClearPlatformUserCacheData struct {
 }
 
 func ClearPlatformUserCache(ctx context.Context, state *state.State, data *ClearPlatformUserCacheData) error {
-	_, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "DELETE",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/platform/user/" + data.ID + api.StructToQueryParamsString(data),
-	})
-
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return api.DoNoContent(ctx, state, clearPlatformUserCache, data)
 }
 
 func init() {
 	api.RegisterTestableRouteCategory(
 		api.NewTestableRouteCategory(
 			"platform",
-			api.CreateTestableRouteWithReqAndResp("getPlatformUser", GetPlatformUser),
-			api.CreateTestableRouteWithOnlyReq("clearPlatformUserCache", ClearPlatformUserCache),
+			api.WithMeta(getPlatformUser, api.CreateTestableRouteWithReqAndResp("getPlatformUser", GetPlatformUser)),
+			api.WithMeta(clearPlatformUserCache, api.CreateTestableRouteWithOnlyReq("clearPlatformUserCache", ClearPlatformUserCache)),
 		),
 	)
 }
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+var ErrMissingPathParam = errors.New("missing path parameter")
+
+var pathParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// The field values of a request keyed by location. Fields behind nil embedded pointers are skipped
+type requestValues struct {
+	path   map[string]reflect.Value
+	query  url.Values
+	body   *reflect.Value
+	fields []RequestField
+	values []reflect.Value
+}
+
+func collectRequestValues(data any) (*requestValues, error) {
+	rv := &requestValues{
+		path:  map[string]reflect.Value{},
+		query: url.Values{},
+	}
+
+	v := reflect.ValueOf(data)
+
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return rv, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return rv, nil
+	}
+
+	for _, f := range RequestFields(v.Type()) {
+		fv, err := v.FieldByIndexErr(f.Index)
+
+		if err != nil {
+			// Nil embedded pointer
+			continue
+		}
+
+		switch f.Location {
+		case LocationPath:
+			rv.path[f.Name] = fv
+		case LocationQuery:
+			if f.OmitEmpty && fv.IsZero() {
+				continue
+			}
+
+			for _, s := range queryStrings(fv) {
+				rv.query.Add(f.Name, s)
+			}
+		case LocationBody:
+			if rv.body != nil {
+				return nil, fmt.Errorf("request type %s has more than one body field", v.Type())
+			}
+
+			rv.body = &fv
+		case LocationBodyField:
+			rv.fields = append(rv.fields, f)
+			rv.values = append(rv.values, fv)
+		}
+	}
+
+	return rv, nil
+}
+
+// Formats a scalar value for use in a URL
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// Returns the query values of a field. Slices are sent as repeated keys and nil pointers are not sent
+func queryStrings(v reflect.Value) []string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		var values []string
+
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, formatValue(v.Index(i)))
+		}
+
+		return values
+	}
+
+	return []string{formatValue(v)}
+}
+
+// BuildURL builds the full URL of a request, substituting `path:` fields of data into the path template
+// and encoding `query:` fields as the query string
+//
+// Path parameters are escaped and query parameters are sorted by key. Query fields tagged omitempty are not sent
+// when they are zero
+func BuildURL(baseUrl string, meta RouteMeta, data any) (string, error) {
+	rv, err := collectRequestValues(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+
+	path := pathParamRegex.ReplaceAllStringFunc(meta.Path, func(m string) string {
+		name := m[1 : len(m)-1]
+
+		v, ok := rv.path[name]
+
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+
+		s := formatValue(v)
+
+		if s == "" {
+			missing = append(missing, name)
+			return m
+		}
+
+		return url.PathEscape(s)
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingPathParam, strings.Join(missing, ", "))
+	}
+
+	u := strings.TrimSuffix(baseUrl, "/") + path
+
+	if len(rv.query) > 0 {
+		// Encode sorts by key
+		u += "?" + rv.query.Encode()
+	}
+
+	return u, nil
+}
+
+// BuildBody returns the JSON body of a request or nil if the request has no body
+//
+// A field tagged `body`, `body:<name>` or `patch` is the entire body. Otherwise, any untagged fields form a
+// JSON object. If the request only consists of such fields, the request itself is sent
+func BuildBody(data any) (io.ReadSeeker, error) {
+	rv, err := collectRequestValues(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rv.body != nil {
+		return fetch.JsonBody(rv.body.Interface())
+	}
+
+	if len(rv.fields) == 0 {
+		return nil, nil
+	}
+
+	if len(RequestFields(reflect.TypeOf(data))) == len(rv.fields) {
+		return fetch.JsonBody(data)
+	}
+
+	var obj = map[string]any{}
+
+	for i, f := range rv.fields {
+		if f.OmitEmpty && rv.values[i].IsZero() {
+			continue
+		}
+
+		obj[f.Name] = rv.values[i].Interface()
+	}
+
+	return fetch.JsonBody(obj)
+}
+
+// BuildFetchOptions builds the fetch options of a request from its RouteMeta and request data
+func BuildFetchOptions(state *state.State, meta RouteMeta, data any) (fetch.FetchOptions, error) {
+	u, err := BuildURL(state.StateFetchOptions.InstanceAPIUrl, meta, data)
+
+	if err != nil {
+		return fetch.FetchOptions{}, err
+	}
+
+	body, err := BuildBody(data)
+
+	if err != nil {
+		return fetch.FetchOptions{}, err
+	}
+
+	return fetch.FetchOptions{
+		Method: meta.Method,
+		URL:    u,
+		Body:   body,
+	}, nil
+}
+
+// Returns the extra fetch options for a route, using the current session if the route needs auth
+func ExtraFetchOptionsFor(state *state.State, meta RouteMeta) fetch.ExtraFetchOptions {
+	if meta.Auth {
+		return fetch.DefaultAuthorizedFetchOptions(state)
+	}
+
+	return fetch.DefaultFetchOptions
+}
+
+// Send makes the request described by meta and data, returning the raw response
+func Send(ctx context.Context, state *state.State, meta RouteMeta, data any) (*fetch.ClientResponse, error) {
+	opts, err := BuildFetchOptions(state, meta, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fetch.Fetch(ctx, &state.StateFetchOptions, ExtraFetchOptionsFor(state, meta), opts)
+}
+
+// Do makes the request described by meta and data, decoding the JSON response into RespType
+func Do[RespType any](ctx context.Context, state *state.State, meta RouteMeta, data any) (*RespType, error) {
+	resp, err := Send(ctx, state, meta, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res RespType
+
+	if err := resp.Json(&res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// DoNoContent makes the request described by meta and data, ignoring the response body
+func DoNoContent(ctx context.Context, state *state.State, meta RouteMeta, data any) error {
+	_, err := Send(ctx, state, meta, data)
+	return err
+}
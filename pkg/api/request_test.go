@@ -0,0 +1,58 @@
+package api
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	GuildID string   `json:"path:guildId"`
+	Name    string   `json:"path:name"`
+	Zeta    bool     `json:"query:zeta"`
+	Alpha   string   `json:"query:alpha,omitempty"`
+	Tags    []string `json:"query:tag,omitempty"`
+	Data    any      `json:"body:data"`
+}
+
+type testBodyFields struct {
+	GuildID string `json:"path:guildId"`
+	Reason  string `json:"reason"`
+	Note    string `json:"note,omitempty"`
+}
+
+func TestBuildURL(t *testing.T) {
+	meta := RouteMeta{Method: "POST", Path: "/guilds/{guildId}/jobs/{name}"}
+
+	u, err := BuildURL("https://example.com/", meta, &testRequest{GuildID: "1", Name: "a b/c", Tags: []string{"x", "y&z"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/guilds/1/jobs/a%20b%2Fc?tag=x&tag=y%26z&zeta=false", u)
+
+	u, err = BuildURL("https://example.com", meta, &testRequest{GuildID: "1", Name: "n", Alpha: "v"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/guilds/1/jobs/n?alpha=v&zeta=false", u)
+
+	_, err = BuildURL("https://example.com", meta, &testRequest{GuildID: "1"})
+	assert.ErrorIs(t, err, ErrMissingPathParam)
+}
+
+func TestBuildBody(t *testing.T) {
+	body, err := BuildBody(&testRequest{GuildID: "1", Name: "n", Data: map[string]any{"a": 1}})
+	assert.NoError(t, err)
+
+	b, _ := io.ReadAll(body)
+	assert.JSONEq(t, `{"a": 1}`, string(b))
+
+	body, err = BuildBody(&testBodyFields{GuildID: "1", Reason: "r"})
+	assert.NoError(t, err)
+
+	b, _ = io.ReadAll(body)
+	assert.JSONEq(t, `{"reason": "r"}`, string(b))
+
+	body, err = BuildBody(&struct {
+		GuildID string `json:"path:guildId"`
+	}{GuildID: "1"})
+	assert.NoError(t, err)
+	assert.Nil(t, body)
+}
@@ -4,32 +4,22 @@ import (
 	"context"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
-	"github.com/anti-raid/evil-befall/pkg/fetch"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types"
 )
 
+var (
+	getUser              = api.RouteMeta{Method: "GET", Path: "/users/{id}", Auth: false, Description: "Returns a user"}
+	getUserGuilds        = api.RouteMeta{Method: "GET", Path: "/users/@me/guilds", Auth: true, Description: "Returns the guilds of the current user"}
+	getUserGuildBaseInfo = api.RouteMeta{Method: "GET", Path: "/users/@me/guilds/{guildId}", Auth: true, Description: "Returns base information about a guild of the current user"}
+)
+
 type GetUserData struct {
 	ID string `json:"path:id"`
 }
 
 func GetUser(ctx context.Context, state *state.State, data *GetUserData) (*types.User, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultFetchOptions, fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/users/" + data.ID,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var user types.User
-
-	if err := resp.Json(&user); err != nil {
-		return nil, err
-	}
-
-	return &user, nil
+	return api.Do[types.User](ctx, state, getUser, data)
 }
 
 type GetUserGuildsData struct {
@@ -37,54 +27,24 @@ type GetUserGuildsData struct {
 }
 
 func GetUserGuilds(ctx context.Context, state *state.State, data *GetUserGuildsData) (*types.DashboardGuildData, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/users/@me/guilds" + api.StructToQueryParamsString(data),
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var guilds types.DashboardGuildData
-
-	if err := resp.Json(&guilds); err != nil {
-		return nil, err
-	}
-
-	return &guilds, nil
+	return api.Do[types.DashboardGuildData](ctx, state, getUserGuilds, data)
 }
 
 type GetUserGuildBaseInfoData struct {
 	GuildID string `json:"path:guildId"`
 }
 
-func GetUserGuildBaseInfo(ctx context.Context, state *state.State, data *GetUserGuildBaseInfoData) (*types.DashboardGuild, error) {
-	resp, err := fetch.Fetch(ctx, &state.StateFetchOptions, fetch.DefaultAuthorizedFetchOptions(state), fetch.FetchOptions{
-		Method: "GET",
-		URL:    state.StateFetchOptions.InstanceAPIUrl + "/users/@me/guilds/" + data.GuildID,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	var guild types.DashboardGuild
-
-	if err := resp.Json(&guild); err != nil {
-		return nil, err
-	}
-
-	return &guild, nil
+func GetUserGuildBaseInfo(ctx context.Context, state *state.State, data *GetUserGuildBaseInfoData) (*types.UserGuildBaseData, error) {
+	return api.Do[types.UserGuildBaseData](ctx, state, getUserGuildBaseInfo, data)
 }
 
 func init() {
 	api.RegisterTestableRouteCategory(
 		api.NewTestableRouteCategory(
 			"user",
-			api.CreateTestableRouteWithReqAndResp("getUser", GetUser),
-			api.CreateTestableRouteWithReqAndResp("getUserGuilds", GetUserGuilds),
-			api.CreateTestableRouteWithReqAndResp("getUserGuildBaseInfo", GetUserGuildBaseInfo),
+			api.WithMeta(getUser, api.CreateTestableRouteWithReqAndResp("getUser", GetUser)),
+			api.WithMeta(getUserGuilds, api.CreateTestableRouteWithReqAndResp("getUserGuilds", GetUserGuilds)),
+			api.WithMeta(getUserGuildBaseInfo, api.CreateTestableRouteWithReqAndResp("getUserGuildBaseInfo", GetUserGuildBaseInfo)),
 		),
 	)
 }
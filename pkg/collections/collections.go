@@ -0,0 +1,286 @@
+// Package collections stores named, ordered lists of apiexec requests that can be replayed
+//
+// Collections are grouped into folders through their names (e.g. audits/modules is the modules collection in the
+// audits folder) and may define variables that requests reference as ${var:name}
+package collections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+)
+
+// The version of the collections file format
+const Version = 1
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrCollectionExists   = errors.New("collection already exists")
+	ErrInvalidName        = errors.New("invalid collection name")
+	ErrUnknownVariable    = errors.New("unknown collection variable")
+)
+
+// The path collections are stored at
+var StorePath = "evil-befall-collections.json"
+
+// Matches $${var:...} (escaped) and ${var:...}
+var varRegex = regexp.MustCompile(`\$?\$\{var:([^}]*)\}`)
+
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9_\-.]+(/[A-Za-z0-9_\-.]+)*$`)
+
+// Request is a single saved apiexec.exec call
+type Request struct {
+	// A label for the request, defaults to the route ID
+	Label string `json:"label"`
+
+	// The TestableRoute ID of the request
+	Route string `json:"route"`
+
+	// The apiexec.exec arguments of the request, excluding route
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// Collection is an ordered list of requests
+type Collection struct {
+	// The name of the collection, including its folders
+	Name string `json:"name"`
+
+	Variables map[string]string `json:"variables,omitempty"`
+	Requests  []*Request        `json:"requests"`
+}
+
+// Returns the folder of the collection, or an empty string if it is not in one
+func (c *Collection) Folder() string {
+	idx := strings.LastIndex(c.Name, "/")
+
+	if idx == -1 {
+		return ""
+	}
+
+	return c.Name[:idx]
+}
+
+// Returns an error for the first request that references a TestableRoute that does not exist
+func (c *Collection) Validate() error {
+	for i, req := range c.Requests {
+		if api.GetTestableRoute(req.Route) == nil {
+			return fmt.Errorf("collection %s: request #%d (%s): %w: %s", c.Name, i+1, req.Label, api.ErrTestableRouteNotFound, req.Route)
+		}
+	}
+
+	return nil
+}
+
+// Store is the set of all collections. This is also the import/export format
+type Store struct {
+	Version     int           `json:"version"`
+	Collections []*Collection `json:"collections"`
+}
+
+// Validates a collection name. Names are /-separated folders ending in the collection name
+func ValidateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("%w: %q (use letters, numbers, _ - . and / to separate folders)", ErrInvalidName, name)
+	}
+
+	return nil
+}
+
+// Loads the store at path. A missing file is an empty store
+func Load(path string) (*Store, error) {
+	f, err := os.Open(path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Store{Version: Version}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open collections: %w", err)
+	}
+
+	defer f.Close()
+
+	return Decode(f)
+}
+
+// Decodes a store, e.g. from an exported file
+func Decode(r io.Reader) (*Store, error) {
+	var s Store
+
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode collections: %w", err)
+	}
+
+	if s.Version > Version {
+		return nil, fmt.Errorf("collections file version %d is newer than supported version %d", s.Version, Version)
+	}
+
+	s.Version = Version
+
+	for _, c := range s.Collections {
+		if err := ValidateName(c.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &s, nil
+}
+
+// Saves the store to path, sorting collections by name so the file diffs well
+func (s *Store) Save(path string) error {
+	sort.Slice(s.Collections, func(i, j int) bool {
+		return s.Collections[i].Name < s.Collections[j].Name
+	})
+
+	b, err := json.MarshalIndent(s, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), ".evil-befall-collections.swp")
+
+	if err := os.WriteFile(tmp, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write collections: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to move collections to final location: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the collection with the given name, or nil
+func (s *Store) Get(name string) *Collection {
+	for _, c := range s.Collections {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Returns the collection with the given name, creating it if it does not exist
+func (s *Store) GetOrCreate(name string) (*Collection, error) {
+	if c := s.Get(name); c != nil {
+		return c, nil
+	}
+
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	c := &Collection{Name: name, Requests: []*Request{}}
+	s.Collections = append(s.Collections, c)
+
+	return c, nil
+}
+
+// Removes a collection, returning whether it existed
+func (s *Store) Remove(name string) bool {
+	idx := slices.IndexFunc(s.Collections, func(c *Collection) bool { return c.Name == name })
+
+	if idx == -1 {
+		return false
+	}
+
+	s.Collections = slices.Delete(s.Collections, idx, idx+1)
+
+	return true
+}
+
+// Returns the collections named prefix or in the folder prefix (and its subfolders). An empty prefix matches everything
+func (s *Store) Match(prefix string) []*Collection {
+	prefix = strings.Trim(prefix, "/")
+
+	var matched []*Collection
+
+	for _, c := range s.Collections {
+		if prefix == "" || c.Name == prefix || strings.HasPrefix(c.Name, prefix+"/") {
+			matched = append(matched, c)
+		}
+	}
+
+	return matched
+}
+
+// Merges the collections of other into s. Existing collections are only replaced if overwrite is set
+func (s *Store) Merge(other *Store, overwrite bool) error {
+	var conflicts []string
+
+	for _, c := range other.Collections {
+		if s.Get(c.Name) != nil {
+			conflicts = append(conflicts, c.Name)
+		}
+	}
+
+	if len(conflicts) > 0 && !overwrite {
+		return fmt.Errorf("%w: %s (set overwrite=true to replace them)", ErrCollectionExists, strings.Join(conflicts, ", "))
+	}
+
+	for _, c := range other.Collections {
+		s.Remove(c.Name)
+		s.Collections = append(s.Collections, c)
+	}
+
+	return nil
+}
+
+// Expands ${var:name} placeholders in s
+//
+// Escaped placeholders ($${var:name}) and other placeholders such as ${env:VAR} are left as is for apiexec.exec
+// to expand
+func ExpandVars(s string, vars map[string]string) (string, error) {
+	var expandErr error
+
+	expanded := varRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m
+		}
+
+		name := varRegex.FindStringSubmatch(m)[1]
+
+		v, ok := vars[name]
+
+		if !ok && expandErr == nil {
+			expandErr = fmt.Errorf("%w: %s", ErrUnknownVariable, name)
+		}
+
+		return v
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	return expanded, nil
+}
+
+// Returns the apiexec.exec arguments of a request with variables expanded
+func (r *Request) ExecArgs(vars map[string]string) (map[string]string, error) {
+	args := map[string]string{"route": r.Route}
+
+	for k, v := range r.Args {
+		expanded, err := ExpandVars(v, vars)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+
+		args[k] = expanded
+	}
+
+	return args, nil
+}
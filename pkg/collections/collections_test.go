@@ -0,0 +1,52 @@
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandVars(t *testing.T) {
+	vars := map[string]string{"guild": "1", "empty": ""}
+
+	s, err := ExpandVars("${var:guild}/x${var:empty}", vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "1/x", s)
+
+	// Escaped and non-var placeholders are left for apiexec.exec
+	s, err = ExpandVars("$${var:guild} ${env:HOME}", vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "$${var:guild} ${env:HOME}", s)
+
+	_, err = ExpandVars("${var:missing}", vars)
+	assert.ErrorIs(t, err, ErrUnknownVariable)
+}
+
+func TestMatch(t *testing.T) {
+	s := &Store{Collections: []*Collection{{Name: "audits"}, {Name: "audits/modules"}, {Name: "audits-old"}, {Name: "smoke"}}}
+
+	names := func(cs []*Collection) []string {
+		var n []string
+		for _, c := range cs {
+			n = append(n, c.Name)
+		}
+		return n
+	}
+
+	assert.Equal(t, []string{"audits", "audits/modules"}, names(s.Match("audits/")))
+	assert.Equal(t, []string{"audits/modules"}, names(s.Match("audits/modules")))
+	assert.Len(t, s.Match(""), 4)
+	assert.Empty(t, s.Match("audit"))
+}
+
+func TestMerge(t *testing.T) {
+	s := &Store{Collections: []*Collection{{Name: "a", Variables: map[string]string{"x": "1"}}}}
+	other := &Store{Collections: []*Collection{{Name: "a", Variables: map[string]string{"x": "2"}}, {Name: "b"}}}
+
+	assert.ErrorIs(t, s.Merge(other, false), ErrCollectionExists)
+	assert.Len(t, s.Collections, 1)
+
+	assert.NoError(t, s.Merge(other, true))
+	assert.Len(t, s.Collections, 2)
+	assert.Equal(t, "2", s.Get("a").Variables["x"])
+}
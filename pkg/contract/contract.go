@@ -0,0 +1,296 @@
+// Package contract checks raw API responses against the Go types they are decoded into
+//
+// Plain json.Unmarshal silently ignores unknown fields and leaves missing ones zeroed, so changes to the
+// API go unnoticed. Check reports unknown fields, missing required fields and type mismatches instead
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api/openapi"
+	"github.com/anti-raid/evil-befall/types/bigint"
+)
+
+var ErrDrift = errors.New("response does not match the expected type")
+
+type IssueKind string
+
+const (
+	// The response has a field that the Go type does not
+	IssueUnknownField IssueKind = "unknown_field"
+
+	// The Go type has a required (non-omitempty) field that the response does not
+	IssueMissingField IssueKind = "missing_field"
+
+	// The JSON type of a value does not match the Go type
+	IssueTypeMismatch IssueKind = "type_mismatch"
+)
+
+// An Issue is a single difference between a response and its Go type
+type Issue struct {
+	Kind IssueKind `json:"kind"`
+
+	// The path to the value, e.g. $.commands[0].name
+	Path string `json:"path"`
+
+	// The expected Go type, empty for unknown fields
+	Expected string `json:"expected,omitempty"`
+
+	// The JSON type of the value, empty for missing fields
+	Got string `json:"got,omitempty"`
+}
+
+func (i Issue) String() string {
+	switch i.Kind {
+	case IssueUnknownField:
+		return fmt.Sprintf("unknown field   %s (%s)", i.Path, i.Got)
+	case IssueMissingField:
+		return fmt.Sprintf("missing field   %s (expected %s)", i.Path, i.Expected)
+	default:
+		return fmt.Sprintf("type mismatch   %s (expected %s, got %s)", i.Path, i.Expected, i.Got)
+	}
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	bigintType = reflect.TypeOf(bigint.BigInt{})
+)
+
+const orderedMapPkg = "github.com/wk8/go-ordered-map/v2"
+
+// Check decodes body and compares it against t, returning all issues found
+//
+// An error is only returned if body is not valid JSON
+func Check(body []byte, t reflect.Type) ([]Issue, error) {
+	var v any
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var issues []Issue
+	check("$", v, t, &issues)
+
+	return issues, nil
+}
+
+// Returns the JSON type of a normalized value
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func mismatch(path string, v any, t reflect.Type, issues *[]Issue) {
+	*issues = append(*issues, Issue{Kind: IssueTypeMismatch, Path: path, Expected: t.String(), Got: jsonKind(v)})
+}
+
+func check(path string, v any, t reflect.Type, issues *[]Issue) {
+	if t == nil {
+		return
+	}
+
+	if t.Kind() == reflect.Ptr {
+		if v == nil {
+			return
+		}
+
+		check(path, v, t.Elem(), issues)
+		return
+	}
+
+	switch {
+	case t == timeType:
+		s, ok := v.(string)
+
+		if !ok {
+			mismatch(path, v, t, issues)
+			return
+		}
+
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			*issues = append(*issues, Issue{Kind: IssueTypeMismatch, Path: path, Expected: "RFC 3339 timestamp", Got: strconv.Quote(s)})
+		}
+
+		return
+	case t == bigintType:
+		if _, ok := v.(string); !ok {
+			if _, ok := v.(json.Number); !ok {
+				mismatch(path, v, t, issues)
+			}
+		}
+
+		return
+	case t.PkgPath() == orderedMapPkg:
+		obj, ok := v.(map[string]any)
+
+		if !ok {
+			if v != nil {
+				mismatch(path, v, t, issues)
+			}
+
+			return
+		}
+
+		get, ok := reflect.PointerTo(t).MethodByName("Get")
+
+		if !ok || get.Type.NumOut() == 0 {
+			return
+		}
+
+		for _, k := range sortedKeys(obj) {
+			check(path+"."+k, obj[k], get.Type.Out(0), issues)
+		}
+
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			mismatch(path, v, t, issues)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(json.Number)
+
+		if !ok {
+			mismatch(path, v, t, issues)
+			return
+		}
+
+		if _, err := strconv.ParseInt(n.String(), 10, t.Bits()); err != nil {
+			*issues = append(*issues, Issue{Kind: IssueTypeMismatch, Path: path, Expected: t.String(), Got: n.String()})
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := v.(json.Number)
+
+		if !ok {
+			mismatch(path, v, t, issues)
+			return
+		}
+
+		if _, err := strconv.ParseUint(n.String(), 10, t.Bits()); err != nil {
+			*issues = append(*issues, Issue{Kind: IssueTypeMismatch, Path: path, Expected: t.String(), Got: n.String()})
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := v.(json.Number); !ok {
+			mismatch(path, v, t, issues)
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			mismatch(path, v, t, issues)
+		}
+	case reflect.Slice, reflect.Array:
+		if v == nil && t.Kind() == reflect.Slice {
+			return // nil slices are encoded as null
+		}
+
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := v.(string); !ok {
+				mismatch(path, v, t, issues)
+			}
+
+			return
+		}
+
+		arr, ok := v.([]any)
+
+		if !ok {
+			mismatch(path, v, t, issues)
+			return
+		}
+
+		for i, elem := range arr {
+			check(fmt.Sprintf("%s[%d]", path, i), elem, t.Elem(), issues)
+		}
+	case reflect.Map:
+		if v == nil {
+			return
+		}
+
+		obj, ok := v.(map[string]any)
+
+		if !ok {
+			mismatch(path, v, t, issues)
+			return
+		}
+
+		for _, k := range sortedKeys(obj) {
+			check(path+"."+k, obj[k], t.Elem(), issues)
+		}
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+
+		if !ok {
+			mismatch(path, v, t, issues)
+			return
+		}
+
+		checkStruct(path, obj, t, issues)
+	}
+}
+
+func checkStruct(path string, obj map[string]any, t reflect.Type, issues *[]Issue) {
+	props := openapi.Properties(t)
+
+	var known = make([]string, 0, len(props))
+
+	for _, p := range props {
+		known = append(known, p.Name)
+
+		val, ok := obj[p.Name]
+
+		if !ok {
+			if p.Required {
+				*issues = append(*issues, Issue{Kind: IssueMissingField, Path: path + "." + p.Name, Expected: p.Type.String()})
+			}
+
+			continue
+		}
+
+		check(path+"."+p.Name, val, p.Type, issues)
+	}
+
+	for _, k := range sortedKeys(obj) {
+		if !slices.Contains(known, k) {
+			*issues = append(*issues, Issue{Kind: IssueUnknownField, Path: path + "." + k, Got: jsonKind(obj[k])})
+		}
+	}
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+
+	for k := range obj {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,40 @@
+package contract
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testInner struct {
+	Level int `json:"level"`
+}
+
+type testResp struct {
+	Name     string            `json:"name"`
+	Optional *string           `json:"optional,omitempty"`
+	Nullable *string           `json:"nullable"`
+	Inner    []testInner       `json:"inner"`
+	Extra    map[string]string `json:"extra"`
+}
+
+func TestCheck(t *testing.T) {
+	typ := reflect.TypeOf(testResp{})
+
+	issues, err := Check([]byte(`{"name": "a", "nullable": null, "inner": [{"level": 1}], "extra": {"k": "v"}}`), typ)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	issues, err = Check([]byte(`{"name": 1, "inner": [{"level": 1.5, "new": true}], "extra": null}`), typ)
+	assert.NoError(t, err)
+	assert.Equal(t, []Issue{
+		{Kind: IssueTypeMismatch, Path: "$.name", Expected: "string", Got: "number"},
+		{Kind: IssueMissingField, Path: "$.nullable", Expected: "*string"},
+		{Kind: IssueTypeMismatch, Path: "$.inner[0].level", Expected: "int", Got: "1.5"},
+		{Kind: IssueUnknownField, Path: "$.inner[0].new", Got: "boolean"},
+	}, issues)
+
+	_, err = Check([]byte(`{`), typ)
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type Status string
+
+const (
+	StatusOk      Status = "ok"
+	StatusDrift   Status = "drift"
+	StatusSkipped Status = "skipped"
+	StatusError   Status = "error"
+)
+
+// Report is the drift report of a single TestableRoute
+type Report struct {
+	RouteID string  `json:"route_id"`
+	Method  string  `json:"method,omitempty"`
+	URL     string  `json:"url,omitempty"`
+	Status  Status  `json:"status"`
+	Reason  string  `json:"reason,omitempty"`
+	Issues  []Issue `json:"issues,omitempty"`
+}
+
+// CheckExchange checks the recorded response of a route against its response type
+func CheckExchange(routeID string, ex *fetch.Exchange, respType reflect.Type) *Report {
+	report := &Report{RouteID: routeID, Method: ex.Method, URL: ex.URL}
+
+	if ex.ErrorType != "" || ex.Status < 200 || ex.Status >= 300 {
+		report.Status = StatusError
+		report.Reason = fmt.Sprintf("request failed with status %d", ex.Status)
+
+		if ex.ErrorType != "" {
+			report.Reason += " (" + ex.ErrorType + ")"
+		}
+
+		return report
+	}
+
+	if len(ex.Body) == 0 {
+		report.Status = StatusOk
+		return report
+	}
+
+	issues, err := Check(ex.Body, respType)
+
+	if err != nil {
+		report.Status = StatusError
+		report.Reason = err.Error()
+		return report
+	}
+
+	report.Issues = issues
+
+	if len(issues) > 0 {
+		report.Status = StatusDrift
+	} else {
+		report.Status = StatusOk
+	}
+
+	return report
+}
+
+// CheckRoute executes a (populated) route and checks its raw response against its response type
+func CheckRoute(ctx context.Context, state *state.State, route api.TestableRoute) *Report {
+	rec := &fetch.Recorder{}
+
+	_, err := route.Exec(fetch.WithRecorder(ctx, rec), state)
+
+	ex := rec.Last()
+
+	if ex == nil {
+		report := &Report{RouteID: route.ID(), Method: route.Meta().Method, Status: StatusSkipped, Reason: "no request was made"}
+
+		switch {
+		case errors.Is(err, api.ErrMissingPathParam):
+			report.Reason = err.Error()
+		case err != nil:
+			report.Status = StatusError
+			report.Reason = err.Error()
+		}
+
+		return report
+	}
+
+	report := CheckExchange(route.ID(), ex, reflect.TypeOf(route.RespType()))
+
+	if report.Status == StatusError && err != nil {
+		report.Reason = err.Error()
+	}
+
+	return report
+}
+
+// Writes a human readable form of the report to w
+func (r *Report) Print(w io.Writer) {
+	var color = ansi.Green
+
+	switch r.Status {
+	case StatusDrift, StatusError:
+		color = ansi.Red
+	case StatusSkipped:
+		color = ansi.Yellow
+	}
+
+	line := fmt.Sprintf("%-8s %s", strings.ToUpper(string(r.Status)), r.RouteID)
+
+	if r.Method != "" && r.URL != "" {
+		line += " (" + r.Method + " " + r.URL + ")"
+	}
+
+	fmt.Fprintln(w, ansi.Color(color, line))
+
+	if r.Reason != "" {
+		fmt.Fprintln(w, "    "+r.Reason)
+	}
+
+	for _, issue := range r.Issues {
+		fmt.Fprintln(w, "    "+issue.String())
+	}
+}
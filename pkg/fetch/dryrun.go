@@ -0,0 +1,69 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+)
+
+// The header set on the synthetic responses of requests that were not sent due to dry-run mode
+const DryRunHeader = "X-Dry-Run"
+
+type readOnlyKey struct{}
+
+// Returns a context whose requests are sent even in dry-run mode, for requests that do not modify data despite
+// not being GETs (e.g. viewing settings)
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func isReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// Shows a request instead of sending it, returning a synthetic response for it
+//
+// The response has a null body so API functions decode it into a zero value instead of failing, letting
+// commands making several requests show all of them
+func dryRun(opts FetchOptions) (*ClientResponse, error) {
+	fmt.Println(ansi.Color(ansi.Yellow, fmt.Sprintf("[dry run] %s %s (not sent)", opts.Method, opts.URL)))
+
+	if opts.Body != nil {
+		body, err := io.ReadAll(opts.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := opts.Body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", "  "); err != nil {
+			buf.Reset()
+			buf.Write(body)
+		}
+
+		fmt.Println(strings.TrimRight(buf.String(), "\n"))
+	}
+
+	return NewClientResponse(&http.Response{
+		Status:     "202 Not Sent (dry run)",
+		StatusCode: http.StatusAccepted,
+		Header:     http.Header{DryRunHeader: []string{"true"}},
+		Body:       io.NopCloser(strings.NewReader("null")),
+	}), nil
+}
+
+// Returns whether the response is a synthetic response for a request that was not sent due to dry-run mode
+func (c *ClientResponse) NotSent() bool {
+	return c.resp.Header.Get(DryRunHeader) == "true"
+}
@@ -54,10 +54,30 @@ type ExtraFetchOptions struct {
 
 var DefaultFetchOptions = ExtraFetchOptions{
 	OnRatelimit: func(fo FetchOptions, retryAfter float64, err error, sfo *state.StateFetchOptions, sess *state.StateSessionAuth) {
-		slog.Info("Ratelimited", slog.String("req", fo.String()), slog.Float64("retryAfter", retryAfter), slog.String("err", err.Error()), slog.Bool("isAuthorized", sess != nil && sess.IsAuthorized()))
+		attrs := []any{slog.String("req", fo.String()), slog.Float64("retryAfter", retryAfter), slog.Bool("isAuthorized", sess != nil && sess.IsAuthorized())}
+
+		// err is only set if the Retry-After header could not be parsed
+		if err != nil {
+			attrs = append(attrs, slog.String("err", err.Error()))
+		}
+
+		slog.Info("Ratelimited", attrs...)
 	},
 }
 
+type noWaitKey struct{}
+
+// Returns a context whose requests return ratelimited responses instead of waiting and retrying, as if
+// ExtraFetchOptions.NoWait was set
+func WithNoWait(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noWaitKey{}, true)
+}
+
+func noWaitFrom(ctx context.Context) bool {
+	noWait, _ := ctx.Value(noWaitKey{}).(bool)
+	return noWait
+}
+
 func DefaultAuthorizedFetchOptions(state *state.State) ExtraFetchOptions {
 	dfo := DefaultFetchOptions
 
@@ -66,6 +86,51 @@ func DefaultAuthorizedFetchOptions(state *state.State) ExtraFetchOptions {
 	return dfo
 }
 
+// ResponseError is the error returned for non-OK responses
+//
+// The message is the same (formatted) message as before, but the status and error type are kept so callers
+// can classify the error
+type ResponseError struct {
+	Status    int
+	ErrorType string
+	Message   string
+	Err       error // The underlying error, if any (e.g. failing to unmarshal the error body)
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// Returns a short classification of an error returned by Fetch, such as the X-Error-Type of the response
+func ErrorType(err error) string {
+	var respErr *ResponseError
+
+	if errors.As(err, &respErr) {
+		if respErr.ErrorType != "" {
+			return respErr.ErrorType
+		}
+
+		return "http_" + strconv.Itoa(respErr.Status)
+	}
+
+	switch {
+	case errors.Is(err, ErrServerMaintenance):
+		return "maintenance"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "cancelled"
+	case errors.Is(err, ErrUnmarshalError):
+		return "decode"
+	case errors.Is(err, ErrNotSent):
+		return "not_sent"
+	}
+
+	return "error"
+}
+
 type ClientResponse struct {
 	resp      *http.Response
 	errorType string
@@ -128,6 +193,17 @@ func (c *ClientResponse) Err() error {
 		panic("fetch: tried to get error from non-error response")
 	}
 
+	err := c.err()
+
+	return &ResponseError{
+		Status:    c.resp.StatusCode,
+		ErrorType: c.errorType,
+		Message:   err.Error(),
+		Err:       errors.Unwrap(err),
+	}
+}
+
+func (c *ClientResponse) err() error {
 	switch c.errorType {
 	case "permission_check":
 		var pr *silverpelt.PermissionResult
@@ -233,6 +309,20 @@ func Fetch(
 			headers["Authorization"] = fmt.Sprintf("User %v", sess.Token)
 		}
 
+		send, err := intercept(ctx, opts, headers)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !send {
+			return nil, ErrNotSent
+		}
+
+		if sfo != nil && sfo.DryRun && opts.Method != http.MethodGet && opts.Method != http.MethodHead && !isReadOnly(ctx) {
+			return dryRun(opts)
+		}
+
 		req, err := http.NewRequestWithContext(ctx, opts.Method, opts.URL, opts.Body)
 
 		if err != nil {
@@ -243,12 +333,20 @@ func Fetch(
 			req.Header.Set(k, v)
 		}
 
+		start := time.Now()
+
 		resp, err := FetchHttpClient.Do(req)
 
 		if err != nil {
 			return nil, err
 		}
 
+		if rec := RecorderFrom(ctx); rec != nil {
+			if err := rec.record(opts, resp, start); err != nil {
+				return nil, err
+			}
+		}
+
 		if slices.Contains([]int{408, 502, 503, 504}, resp.StatusCode) {
 			return nil, ErrServerMaintenance
 		}
@@ -271,8 +369,12 @@ func Fetch(
 			}
 
 			// Wait for the time specified by the server
-			if !efo.NoWait {
-				time.Sleep(time.Duration(retryAfter) * time.Second)
+			if !efo.NoWait && !noWaitFrom(ctx) {
+				select {
+				case <-time.After(time.Duration(retryAfter * float64(time.Millisecond))):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
 
 				if opts.Body != nil {
 					if _, err := opts.Body.Seek(0, io.SeekStart); err != nil {
@@ -0,0 +1,61 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotSent is returned by Fetch for requests that an Interceptor stopped from being sent
+var ErrNotSent = errors.New("fetch: request not sent")
+
+type interceptorKey struct{}
+
+// Request is a request exactly as Fetch sends it
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// An Interceptor is called with every request made with a context from WithInterceptor before it is sent.
+// Returning false stops the request from being sent, making Fetch return ErrNotSent
+type Interceptor func(req *Request) bool
+
+// Returns a context whose requests are passed to fn before being sent
+func WithInterceptor(ctx context.Context, fn Interceptor) context.Context {
+	return context.WithValue(ctx, interceptorKey{}, fn)
+}
+
+func interceptorFrom(ctx context.Context) Interceptor {
+	fn, _ := ctx.Value(interceptorKey{}).(Interceptor)
+	return fn
+}
+
+// Calls the interceptor of ctx (if any) with the request, returning whether it should be sent
+func intercept(ctx context.Context, opts FetchOptions, headers map[string]string) (bool, error) {
+	fn := interceptorFrom(ctx)
+
+	if fn == nil {
+		return true, nil
+	}
+
+	req := &Request{Method: opts.Method, URL: opts.URL, Headers: headers}
+
+	if opts.Body != nil {
+		body, err := io.ReadAll(opts.Body)
+
+		if err != nil {
+			return false, err
+		}
+
+		if _, err := opts.Body.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+
+		req.Body = body
+	}
+
+	return fn(req), nil
+}
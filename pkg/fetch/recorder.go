@@ -0,0 +1,111 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type recorderKey struct{}
+
+// Exchange is a recorded request/response pair
+type Exchange struct {
+	Method      string
+	URL         string
+	RequestBody []byte
+
+	Status    int
+	ErrorType string
+	Headers   http.Header
+	Body      []byte
+
+	// How long the request took, including reading the body
+	Duration time.Duration
+}
+
+// Recorder records the raw exchanges of all requests made with a context from WithRecorder
+//
+// This allows callers such as contract checks to inspect the raw response body even though API functions
+// only return the decoded response
+type Recorder struct {
+	mu        sync.Mutex
+	exchanges []*Exchange
+}
+
+// Returns a context that records all requests made with it into rec
+func WithRecorder(ctx context.Context, rec *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, rec)
+}
+
+// Returns the recorder of a context, or nil if there is none
+func RecorderFrom(ctx context.Context) *Recorder {
+	rec, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return rec
+}
+
+// Returns all recorded exchanges
+func (r *Recorder) Exchanges() []*Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*Exchange{}, r.exchanges...)
+}
+
+// Returns the last recorded exchange, or nil if no request was made
+func (r *Recorder) Last() *Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.exchanges) == 0 {
+		return nil
+	}
+
+	return r.exchanges[len(r.exchanges)-1]
+}
+
+// Records a response, replacing its body with an in-memory copy so it can still be read by the caller
+func (r *Recorder) record(opts FetchOptions, resp *http.Response, start time.Time) error {
+	ex := &Exchange{
+		Method:    opts.Method,
+		URL:       opts.URL,
+		Status:    resp.StatusCode,
+		ErrorType: resp.Header.Get("X-Error-Type"),
+		Headers:   resp.Header,
+	}
+
+	if opts.Body != nil {
+		if _, err := opts.Body.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		reqBody, err := io.ReadAll(opts.Body)
+
+		if err != nil {
+			return err
+		}
+
+		ex.RequestBody = reqBody
+	}
+
+	//nolint:errcheck
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	ex.Body = body
+	ex.Duration = time.Since(start)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, ex)
+	r.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,249 @@
+// Package fuzz generates boundary and malformed values for the fields of a request type and classifies the
+// responses of requests made with them
+package fuzz
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/openapi"
+)
+
+// How long huge strings are
+const hugeStringLength = 100_000
+
+// How many elements huge arrays have
+const hugeArrayLength = 10_000
+
+// How deeply nested the deeply nested JSON value is
+const nestingDepth = 512
+
+// How many levels of nested structs are fuzzed, e.g. body.fields is one level below body
+const maxDepth = 2
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Case is a single value to send in place of a field
+type Case struct {
+	// The path of the field, e.g. path:guildId or body.fields. The first segment is the request field key
+	Field string `json:"field"`
+
+	// A short name for the case, e.g. empty or invalid_snowflake
+	Name string `json:"name"`
+
+	Value any `json:"value"`
+
+	// Whether a correct server must reject the value. Successes of such cases are flagged
+	ExpectReject bool `json:"expect_reject"`
+}
+
+// Returns the cases for all fields of a request type
+func Cases(reqType reflect.Type) []Case {
+	var cases []Case
+
+	for _, f := range api.RequestFields(reqType) {
+		cases = append(cases, fieldCases(f.Key, f.Name, f.Type, f.Required(), 0)...)
+	}
+
+	return cases
+}
+
+func fieldCases(path, name string, t reflect.Type, required bool, depth int) []Case {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var cases []Case
+	add := func(caseName string, value any, expectReject bool) {
+		cases = append(cases, Case{Field: path, Name: caseName, Value: value, ExpectReject: expectReject})
+	}
+
+	if t == timeType {
+		add("invalid_time", "not-a-time", true)
+		add("zero_time", "0001-01-01T00:00:00Z", false)
+		add("far_future_time", "9999-12-31T23:59:59Z", false)
+		add("wrong_shape_number", 0, true)
+		return cases
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if isSnowflake(name) {
+			add("empty", "", required)
+			add("zero_snowflake", "0", true)
+			add("negative_snowflake", "-1", true)
+			add("non_numeric_snowflake", "abc", true)
+			add("overflowing_snowflake", "18446744073709551616", true)
+			add("fractional_snowflake", "1.5", true)
+			add("huge_snowflake", strings.Repeat("9", hugeStringLength), true)
+		} else {
+			add("empty", "", required)
+			add("huge", strings.Repeat("a", hugeStringLength), false)
+			add("whitespace", " \t\n ", false)
+			add("control_chars", "\x00\x1b[31m‮", false)
+			add("path_traversal", "../../../etc/passwd", false)
+			add("sql_injection", "' OR '1'='1' --", false)
+			add("html", "<script>alert(1)</script>", false)
+			add("unicode", "𝔘𝔫𝔦𝔠𝔬𝔡𝔢 ✓ 中文 🙂", false)
+		}
+
+		add("wrong_shape_number", 1, true)
+		add("wrong_shape_object", map[string]any{}, true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		add("zero", 0, false)
+		add("negative", -1, false)
+		add("max_int64", json.Number("9223372036854775807"), false)
+		add("min_int64", json.Number("-9223372036854775808"), false)
+		add("overflowing", json.Number("9223372036854775808"), true)
+		add("fractional", 1.5, true)
+		add("wrong_shape_string", "1", true)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		add("zero", 0, false)
+		add("negative", -1, true)
+		add("max_uint64", json.Number("18446744073709551615"), false)
+		add("overflowing", json.Number("18446744073709551616"), true)
+		add("wrong_shape_string", "1", true)
+	case reflect.Float32, reflect.Float64:
+		add("zero", 0, false)
+		add("negative", -1, false)
+		add("huge", json.Number("1e308"), false)
+		add("tiny", json.Number("5e-324"), false)
+		add("wrong_shape_string", "1", true)
+	case reflect.Bool:
+		add("wrong_shape_string", "true", true)
+		add("wrong_shape_number", 1, true)
+		add("null", nil, required)
+	case reflect.Slice, reflect.Array:
+		add("empty", []any{}, required)
+		add("null", nil, required)
+		add("huge", hugeArray(t.Elem()), false)
+		add("wrong_shape_object", map[string]any{}, true)
+		add("wrong_shape_string", "x", true)
+	case reflect.Map:
+		add("empty", map[string]any{}, false)
+		add("null", nil, required)
+		add("empty_key", map[string]any{"": zeroValue(t.Elem())}, false)
+		add("wrong_shape_array", []any{}, true)
+		add("wrong_shape_string", "x", true)
+	case reflect.Struct:
+		add("empty", map[string]any{}, required)
+		add("null", nil, required)
+		add("wrong_shape_array", []any{}, true)
+		add("wrong_shape_string", "x", true)
+
+		if depth < maxDepth {
+			for _, p := range openapi.Properties(t) {
+				cases = append(cases, fieldCases(path+"."+p.Name, p.Name, p.Type, p.Required, depth+1)...)
+			}
+		}
+	case reflect.Interface:
+		add("null", nil, required)
+		add("empty_string", "", false)
+		add("number", 0, false)
+		add("empty_array", []any{}, false)
+		add("empty_object", map[string]any{}, false)
+		add("deeply_nested", deeplyNested(), false)
+		add("huge_array", hugeArray(t), false)
+	}
+
+	return cases
+}
+
+// Returns whether a field holds a Discord snowflake (or other numeric ID) going by its name
+func isSnowflake(name string) bool {
+	for _, suffix := range []string{"Id", "ID", "_id", "Ids", "IDs", "_ids"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return strings.EqualFold(name, "id")
+}
+
+// Returns a JSON-compatible zero value for a type
+func zeroValue(t reflect.Type) any {
+	b, err := json.Marshal(reflect.Zero(t).Interface())
+
+	if err != nil {
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+
+	return v
+}
+
+func hugeArray(elem reflect.Type) []any {
+	zero := zeroValue(elem)
+
+	arr := make([]any, hugeArrayLength)
+	for i := range arr {
+		arr[i] = zero
+	}
+
+	return arr
+}
+
+func deeplyNested() any {
+	var v any = []any{}
+	for range nestingDepth {
+		v = []any{v}
+	}
+
+	return v
+}
+
+// Returns a copy of base with the field of the case set to its value
+//
+// Nested fields (e.g. body.fields) create the objects above them if base does not set them
+func Apply(base map[string]any, c Case) map[string]any {
+	fields, ok := deepCopy(base).(map[string]any)
+
+	if !ok {
+		fields = map[string]any{}
+	}
+
+	segments := strings.Split(c.Field, ".")
+
+	m := fields
+	for _, seg := range segments[:len(segments)-1] {
+		next, ok := m[seg].(map[string]any)
+
+		if !ok {
+			next = map[string]any{}
+			m[seg] = next
+		}
+
+		m = next
+	}
+
+	m[segments[len(segments)-1]] = c.Value
+
+	return fields
+}
+
+// Deep copies a value made of maps and slices, other values are shared
+func deepCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(t))
+		for k, val := range t {
+			c[k] = deepCopy(val)
+		}
+		return c
+	case []any:
+		c := make([]any, len(t))
+		for i, val := range t {
+			c[i] = deepCopy(val)
+		}
+		return c
+	}
+
+	return v
+}
@@ -0,0 +1,68 @@
+package fuzz
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSettings struct {
+	Operation string         `json:"operation"`
+	Fields    map[string]any `json:"fields"`
+}
+
+type testRequest struct {
+	GuildID string        `json:"path:guildId"`
+	Limit   uint          `json:"query:limit,omitempty"`
+	Body    *testSettings `json:"body"`
+}
+
+func TestCases(t *testing.T) {
+	cases := Cases(reflect.TypeOf(testRequest{}))
+
+	find := func(field, name string) *Case {
+		for _, c := range cases {
+			if c.Field == field && c.Name == name {
+				return &c
+			}
+		}
+		return nil
+	}
+
+	assert.True(t, find("path:guildId", "empty").ExpectReject, "path params are required")
+	assert.True(t, find("path:guildId", "non_numeric_snowflake").ExpectReject)
+	assert.Nil(t, find("path:guildId", "huge"), "snowflakes get snowflake cases")
+	assert.False(t, find("query:limit", "zero").ExpectReject)
+	assert.True(t, find("query:limit", "negative").ExpectReject)
+	assert.NotNil(t, find("body", "wrong_shape_array"))
+	assert.NotNil(t, find("body.fields", "empty_key"), "nested struct fields are fuzzed")
+	assert.NotNil(t, find("body.operation", "sql_injection"))
+}
+
+func TestApply(t *testing.T) {
+	base := map[string]any{"path:guildId": "1", "body": map[string]any{"operation": "View"}}
+
+	fields := Apply(base, Case{Field: "body.fields", Value: "x"})
+
+	assert.Equal(t, map[string]any{"operation": "View", "fields": "x"}, fields["body"])
+	assert.Equal(t, map[string]any{"operation": "View"}, base["body"], "base is not modified")
+
+	fields = Apply(nil, Case{Field: "body.operation", Value: nil})
+	assert.Equal(t, map[string]any{"body": map[string]any{"operation": nil}}, fields)
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, OutcomeServerError, Classify(500, errors.New("boom")))
+	assert.Equal(t, OutcomeServerError, Classify(0, fetch.ErrServerMaintenance))
+	assert.Equal(t, OutcomeRejected, Classify(400, errors.New("bad")))
+	assert.Equal(t, OutcomeAccepted, Classify(200, nil))
+	assert.Equal(t, OutcomeTransportError, Classify(0, &url.Error{Op: "Get", Err: errors.New("timeout")}))
+	assert.Equal(t, OutcomeClientRejected, Classify(0, errors.New("json: cannot unmarshal")))
+
+	assert.Equal(t, "unexpected success", Case{ExpectReject: true}.Finding(OutcomeAccepted))
+	assert.Equal(t, "", Case{}.Finding(OutcomeAccepted))
+}
@@ -0,0 +1,69 @@
+package fuzz
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+)
+
+// How a request with a fuzzed value was handled
+type Outcome string
+
+const (
+	// The value was rejected before sending, e.g. by the Go types or a missing path parameter
+	OutcomeClientRejected Outcome = "client_rejected"
+
+	// The server rejected the value with a 4xx response
+	OutcomeRejected Outcome = "rejected"
+
+	// The server accepted the value
+	OutcomeAccepted Outcome = "accepted"
+
+	// The server responded with a 5xx response (including maintenance responses)
+	OutcomeServerError Outcome = "server_error"
+
+	// The request was sent but no response was received, e.g. due to a timeout
+	OutcomeTransportError Outcome = "transport_error"
+)
+
+// Returns the outcome of a request from its final response status (0 if there was none) and error
+func Classify(status int, err error) Outcome {
+	switch {
+	case status >= 500 || errors.Is(err, fetch.ErrServerMaintenance):
+		return OutcomeServerError
+	case status >= 400:
+		return OutcomeRejected
+	case status > 0:
+		if err != nil && !errors.Is(err, fetch.ErrUnmarshalError) {
+			// Error types on 2xx responses are still errors
+			return OutcomeRejected
+		}
+
+		return OutcomeAccepted
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded) {
+		return OutcomeTransportError
+	}
+
+	return OutcomeClientRejected
+}
+
+// Returns why a case with the given outcome should be looked at, or an empty string if it is fine
+func (c Case) Finding(outcome Outcome) string {
+	switch outcome {
+	case OutcomeServerError:
+		return "server error"
+	case OutcomeTransportError:
+		return "no response"
+	case OutcomeAccepted:
+		if c.ExpectReject {
+			return "unexpected success"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,302 @@
+// Package jsontree parses JSON into a tree of nodes, keeping the key order of objects
+//
+// Unlike decoding into map[string]any, this keeps the order of types such as orderedmap.OrderedMap which
+// marshal their keys in insertion order
+package jsontree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Kind int
+
+const (
+	KindScalar Kind = iota
+	KindObject
+	KindArray
+)
+
+// Node is a JSON value in a tree
+type Node struct {
+	// The key of the node in its parent object, or its index in its parent array. Empty for the root
+	Key string
+
+	Kind Kind
+
+	// The JSON encoding of scalar values, e.g. "abc" (with quotes), 1.5, true or null
+	Scalar string
+
+	// The children of objects and arrays, in order
+	Children []*Node
+
+	Parent *Node
+}
+
+// Parse parses a single JSON value
+func Parse(data []byte) (*Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	root, err := parse(dec, "", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+
+	return root, nil
+}
+
+// FromValue returns the tree of the JSON encoding of v
+func FromValue(v any) (*Node, error) {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(b)
+}
+
+func parse(dec *json.Decoder, key string, parent *Node) (*Node, error) {
+	tok, err := dec.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{Key: key, Parent: parent}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n.Kind = KindObject
+
+			for dec.More() {
+				keyTok, err := dec.Token()
+
+				if err != nil {
+					return nil, err
+				}
+
+				child, err := parse(dec, keyTok.(string), n)
+
+				if err != nil {
+					return nil, err
+				}
+
+				n.Children = append(n.Children, child)
+			}
+		case '[':
+			n.Kind = KindArray
+
+			for i := 0; dec.More(); i++ {
+				child, err := parse(dec, strconv.Itoa(i), n)
+
+				if err != nil {
+					return nil, err
+				}
+
+				n.Children = append(n.Children, child)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected delimiter %s", t)
+		}
+
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	case json.Number:
+		n.Scalar = t.String()
+	case nil:
+		n.Scalar = "null"
+	default:
+		b, err := json.Marshal(t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.Scalar = string(b)
+	}
+
+	return n, nil
+}
+
+// Returns the nodes from below the root down to n
+func (n *Node) lineage() []*Node {
+	var nodes []*Node
+
+	for c := n; c.Parent != nil; c = c.Parent {
+		nodes = append([]*Node{c}, nodes...)
+	}
+
+	return nodes
+}
+
+// PathString returns the path of the node in $.a[0].b form, quoting keys that are not identifiers
+func (n *Node) PathString() string {
+	var sb strings.Builder
+	sb.WriteString("$")
+
+	for _, c := range n.lineage() {
+		switch {
+		case c.Parent.Kind == KindArray:
+			sb.WriteString("[" + c.Key + "]")
+		case isIdentifier(c.Key):
+			sb.WriteString("." + c.Key)
+		default:
+			sb.WriteString("[" + strconv.Quote(c.Key) + "]")
+		}
+	}
+
+	return sb.String()
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// MarshalJSON encodes the node, keeping the key order of objects
+func (n *Node) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := n.encode(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (n *Node) encode(buf *bytes.Buffer) error {
+	switch n.Kind {
+	case KindObject:
+		buf.WriteByte('{')
+
+		for i, c := range n.Children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, err := json.Marshal(c.Key)
+
+			if err != nil {
+				return err
+			}
+
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			if err := c.encode(buf); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+	case KindArray:
+		buf.WriteByte('[')
+
+		for i, c := range n.Children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := c.encode(buf); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+	default:
+		buf.WriteString(n.Scalar)
+	}
+
+	return nil
+}
+
+// Indent returns the indented JSON encoding of the node
+func (n *Node) Indent() ([]byte, error) {
+	b, err := n.MarshalJSON()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Summary returns a short description of the value of the node, e.g. "abc", {3 keys} or [10 items]
+func (n *Node) Summary() string {
+	switch n.Kind {
+	case KindObject:
+		return plural(len(n.Children), "key", "{", "}")
+	case KindArray:
+		return plural(len(n.Children), "item", "[", "]")
+	}
+
+	return n.Scalar
+}
+
+func plural(count int, noun, open, close string) string {
+	if count != 1 {
+		noun += "s"
+	}
+
+	return fmt.Sprintf("%s%d %s%s", open, count, noun, close)
+}
+
+// Search returns the nodes below n (including n) whose key or scalar value contains the query, in document order.
+// Matching is case-insensitive
+func (n *Node) Search(query string) []*Node {
+	query = strings.ToLower(query)
+
+	if query == "" {
+		return nil
+	}
+
+	var matches []*Node
+
+	var walk func(c *Node)
+	walk = func(c *Node) {
+		if strings.Contains(strings.ToLower(c.Key), query) || (c.Kind == KindScalar && strings.Contains(strings.ToLower(c.Scalar), query)) {
+			matches = append(matches, c)
+		}
+
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+
+	walk(n)
+
+	return matches
+}
@@ -0,0 +1,54 @@
+package jsontree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestParseKeepsOrder(t *testing.T) {
+	om := orderedmap.New[string, any]()
+	om.Set("z", 1)
+	om.Set("a", []any{"x", nil})
+	om.Set("m", map[string]any{"weird key": true})
+
+	root, err := FromValue(om)
+	assert.NoError(t, err)
+
+	var keys []string
+	for _, c := range root.Children {
+		keys = append(keys, c.Key)
+	}
+	assert.Equal(t, []string{"z", "a", "m"}, keys)
+
+	b, err := root.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"z":1,"a":["x",null],"m":{"weird key":true}}`, string(b))
+
+	assert.Equal(t, "{3 keys}", root.Summary())
+	assert.Equal(t, "[2 items]", root.Children[1].Summary())
+	assert.Equal(t, `"x"`, root.Children[1].Children[0].Summary())
+
+	_, err = Parse([]byte(`{"a":1} {}`))
+	assert.Error(t, err)
+}
+
+func TestPathAndSearch(t *testing.T) {
+	root, err := Parse([]byte(`{"statuses":[{"level":"ok"},{"level":"Warn","weird key":1}]}`))
+	assert.NoError(t, err)
+
+	matches := root.Search("warn")
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "$.statuses[1].level", matches[0].PathString())
+
+	matches = root.Search("level")
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "$.statuses[0].level", matches[0].PathString())
+
+	matches = root.Search("weird")
+	assert.Equal(t, `$.statuses[1]["weird key"]`, matches[0].PathString())
+
+	assert.Equal(t, "$", root.PathString())
+	assert.Empty(t, root.Search(""))
+}
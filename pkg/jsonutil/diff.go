@@ -0,0 +1,268 @@
+package jsonutil
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// The kind of a change in a structural diff
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+// A single difference between two JSON values
+type Change struct {
+	Kind ChangeKind
+
+	// The path of the change. Elements of arrays of objects with unique ids are addressed as id=<id>
+	// instead of by index so that insertions do not show up as changes to every following element
+	Path string
+
+	// The left value, unset for added values
+	Left any
+
+	// The right value, unset for removed values
+	Right any
+}
+
+// The value ignored values are replaced with by Mask
+const Masked = "<ignored>"
+
+// Mask replaces all values matching any of patterns with Masked, in place. v must be normalized
+//
+// Patterns are paths (see SplitPath) where * matches any single segment and ** matches any number of
+// segments, e.g. **.created_at or commands.*.id. Values are replaced rather than removed so that a
+// field going missing is still reported by Diff
+func Mask(v any, patterns []string) any {
+	var split [][]string
+	for _, p := range patterns {
+		if segs := SplitPath(p); len(segs) > 0 {
+			split = append(split, segs)
+		}
+	}
+
+	if len(split) == 0 {
+		return v
+	}
+
+	return mask(v, nil, split)
+}
+
+func mask(v any, path []string, patterns [][]string) any {
+	for _, p := range patterns {
+		if matchPath(p, path) {
+			return Masked
+		}
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = mask(val, append(path, k), patterns)
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = mask(val, append(path, fmt.Sprint(i)), patterns)
+		}
+	}
+
+	return v
+}
+
+func matchPath(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchPath(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(path) == 0 || (pattern[0] != "*" && pattern[0] != path[0]) {
+		return false
+	}
+
+	return matchPath(pattern[1:], path[1:])
+}
+
+// Diff returns the structural differences turning a into b. Both values must be normalized
+//
+// Changes are sorted by path
+func Diff(a, b any) []Change {
+	var changes []Change
+	diff(a, b, nil, &changes)
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+func diff(a, b any, path []string, changes *[]Change) {
+	switch at := a.(type) {
+	case map[string]any:
+		bt, ok := b.(map[string]any)
+
+		if !ok {
+			*changes = append(*changes, Change{Kind: ChangeModified, Path: JoinPath(path), Left: a, Right: b})
+			return
+		}
+
+		for k, av := range at {
+			bv, ok := bt[k]
+
+			if !ok {
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: JoinPath(append(path, k)), Left: av})
+				continue
+			}
+
+			diff(av, bv, append(path, k), changes)
+		}
+
+		for k, bv := range bt {
+			if _, ok := at[k]; !ok {
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: JoinPath(append(path, k)), Right: bv})
+			}
+		}
+
+		return
+	case []any:
+		bt, ok := b.([]any)
+
+		if !ok {
+			*changes = append(*changes, Change{Kind: ChangeModified, Path: JoinPath(path), Left: a, Right: b})
+			return
+		}
+
+		aKeys, bKeys := elementIds(at), elementIds(bt)
+
+		if aKeys == nil || bKeys == nil {
+			for i := 0; i < max(len(at), len(bt)); i++ {
+				elemPath := append(path, fmt.Sprint(i))
+
+				switch {
+				case i >= len(bt):
+					*changes = append(*changes, Change{Kind: ChangeRemoved, Path: JoinPath(elemPath), Left: at[i]})
+				case i >= len(at):
+					*changes = append(*changes, Change{Kind: ChangeAdded, Path: JoinPath(elemPath), Right: bt[i]})
+				default:
+					diff(at[i], bt[i], elemPath, changes)
+				}
+			}
+
+			return
+		}
+
+		for i, id := range aKeys {
+			elemPath := append(path, "id="+id)
+
+			j := slices.Index(bKeys, id)
+
+			if j == -1 {
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: JoinPath(elemPath), Left: at[i]})
+				continue
+			}
+
+			diff(at[i], bt[j], elemPath, changes)
+		}
+
+		for j, id := range bKeys {
+			if !slices.Contains(aKeys, id) {
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: JoinPath(append(path, "id="+id)), Right: bt[j]})
+			}
+		}
+
+		return
+	}
+
+	if !Equal(a, b) {
+		*changes = append(*changes, Change{Kind: ChangeModified, Path: JoinPath(path), Left: a, Right: b})
+	}
+}
+
+// Returns the ids of the elements of an array if all elements are objects with a unique scalar id, otherwise nil
+//
+// An empty array has no ids but can still be matched against a keyed array
+func elementIds(arr []any) []string {
+	ids := make([]string, 0, len(arr))
+
+	for _, v := range arr {
+		obj, ok := v.(map[string]any)
+
+		if !ok {
+			return nil
+		}
+
+		id, ok := obj["id"]
+
+		if !ok {
+			return nil
+		}
+
+		switch id.(type) {
+		case map[string]any, []any, nil:
+			return nil
+		}
+
+		idStr := ScalarString(id)
+
+		if slices.Contains(ids, idStr) {
+			return nil
+		}
+
+		ids = append(ids, idStr)
+	}
+
+	return ids
+}
+
+// Equal returns whether two normalized JSON values are equal
+func Equal(a, b any) bool {
+	var changes []Change
+
+	switch a.(type) {
+	case map[string]any, []any:
+		diff(a, b, nil, &changes)
+		return len(changes) == 0
+	}
+
+	switch b.(type) {
+	case map[string]any, []any:
+		return false
+	}
+
+	if (a == nil) != (b == nil) {
+		return false
+	}
+
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b) && ScalarString(a) == ScalarString(b)
+}
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+
+	return "unknown"
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
@@ -0,0 +1,182 @@
+// Package jsonutil provides helpers for working with arbitrary JSON values such as normalizing, path lookups and diffing
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Normalize converts any value to its generic JSON form (map[string]any, []any, string, float64, bool or nil)
+//
+// This drops Go-specific ordering and types so that two values can be compared structurally
+func Normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var n any
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if err := dec.Decode(&n); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// Indent returns the normalized, indented JSON form of a value. Object keys are sorted
+func Indent(v any) (string, error) {
+	n, err := Normalize(v)
+
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.MarshalIndent(n, "", "  ")
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Splits a path such as `statuses.0.level`, `$.statuses[0].level` or `/statuses/0/level` into its segments
+func SplitPath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+
+	var segments []string
+	for _, s := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '/' || r == '[' || r == ']' }) {
+		segments = append(segments, s)
+	}
+
+	return segments
+}
+
+// Joins path segments back into a dotted path
+func JoinPath(segments []string) string {
+	if len(segments) == 0 {
+		return "$"
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// Lookup returns the value at path in a normalized JSON value
+func Lookup(v any, path string) (any, bool) {
+	for _, seg := range SplitPath(path) {
+		switch t := v.(type) {
+		case map[string]any:
+			val, ok := t[seg]
+
+			if !ok {
+				return nil, false
+			}
+
+			v = val
+		case []any:
+			idx, err := strconv.Atoi(seg)
+
+			if err != nil || idx < 0 || idx >= len(t) {
+				return nil, false
+			}
+
+			v = t[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// Returns the string form of a normalized JSON value for comparisons with user input
+//
+// Strings are returned as is, everything else as its JSON encoding
+func ScalarString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return "null"
+	case json.Number:
+		return t.String()
+	}
+
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// The kind of a line in a line diff
+type LineOpKind int
+
+const (
+	LineSame LineOpKind = iota
+	LineAdded
+	LineRemoved
+)
+
+type LineOp struct {
+	Kind LineOpKind
+	Line string
+}
+
+// DiffLines returns a line diff (based on the longest common subsequence) turning a into b
+func DiffLines(a, b []string) []LineOp {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []LineOp
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, LineOp{Kind: LineSame, Line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, LineOp{Kind: LineRemoved, Line: a[i]})
+			i++
+		default:
+			ops = append(ops, LineOp{Kind: LineAdded, Line: b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		ops = append(ops, LineOp{Kind: LineRemoved, Line: a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		ops = append(ops, LineOp{Kind: LineAdded, Line: b[j]})
+	}
+
+	return ops
+}
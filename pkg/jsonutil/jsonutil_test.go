@@ -0,0 +1,90 @@
+package jsonutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	v, err := Normalize(map[string]any{
+		"state":    "completed",
+		"statuses": []map[string]any{{"level": "info"}, {"level": "error"}},
+	})
+	assert.NoError(t, err)
+
+	got, ok := Lookup(v, "state")
+	assert.True(t, ok)
+	assert.Equal(t, "completed", ScalarString(got))
+
+	got, ok = Lookup(v, "$.statuses[1].level")
+	assert.True(t, ok)
+	assert.Equal(t, "error", ScalarString(got))
+
+	_, ok = Lookup(v, "statuses.5.level")
+	assert.False(t, ok)
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := DiffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+
+	assert.Equal(t, []LineOp{
+		{Kind: LineSame, Line: "a"},
+		{Kind: LineRemoved, Line: "b"},
+		{Kind: LineAdded, Line: "x"},
+		{Kind: LineSame, Line: "c"},
+		{Kind: LineAdded, Line: "d"},
+	}, ops)
+}
+
+func TestMask(t *testing.T) {
+	v, err := Normalize(map[string]any{
+		"created_at": "now",
+		"modules":    []map[string]any{{"id": "core", "created_at": "now", "name": "Core"}},
+	})
+	assert.NoError(t, err)
+
+	v = Mask(v, []string{"**.created_at", "modules.*.name"})
+
+	got, _ := Lookup(v, "created_at")
+	assert.Equal(t, Masked, got)
+	got, _ = Lookup(v, "modules.0.created_at")
+	assert.Equal(t, Masked, got)
+	got, _ = Lookup(v, "modules.0.name")
+	assert.Equal(t, Masked, got)
+	got, _ = Lookup(v, "modules.0.id")
+	assert.Equal(t, "core", got)
+}
+
+func TestDiff(t *testing.T) {
+	a, err := Normalize(map[string]any{
+		"version": 1,
+		"gone":    true,
+		"modules": []map[string]any{{"id": "core", "commands": []string{"ping"}}, {"id": "mod"}},
+		"tags":    []string{"a"},
+	})
+	assert.NoError(t, err)
+
+	b, err := Normalize(map[string]any{
+		"version": "1",
+		"modules": []map[string]any{{"id": "new"}, {"id": "core", "commands": []string{"ping", "help"}}, {"id": "mod"}},
+		"tags":    map[string]any{},
+	})
+	assert.NoError(t, err)
+
+	var got []string
+	for _, c := range Diff(a, b) {
+		got = append(got, fmt.Sprintf("%d %s", c.Kind, c.Path))
+	}
+
+	assert.Equal(t, []string{
+		fmt.Sprintf("%d gone", ChangeRemoved),
+		fmt.Sprintf("%d modules.id=core.commands.1", ChangeAdded),
+		fmt.Sprintf("%d modules.id=new", ChangeAdded),
+		fmt.Sprintf("%d tags", ChangeModified),
+		fmt.Sprintf("%d version", ChangeModified),
+	}, got)
+
+	assert.Empty(t, Diff(a, a))
+}
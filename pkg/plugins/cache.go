@@ -0,0 +1,103 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A cached description of a plugin, valid while the executable has the same modification time and size
+type cacheEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+
+	// The description of the plugin, unset if it failed to describe itself
+	Desc *PluginDescription `json:"desc,omitempty"`
+
+	// Why the plugin cannot be used, so broken plugins are not run again until they change
+	Err string `json:"err,omitempty"`
+}
+
+// DescriptionCache caches the descriptions of plugins on disk, so plugins are only run in describe mode when
+// they are new or have changed
+type DescriptionCache struct {
+	path    string
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+// Loads the description cache stored at path. A missing or unreadable cache is treated as empty. An empty path
+// gives a cache that is never saved
+func LoadDescriptionCache(path string) *DescriptionCache {
+	c := &DescriptionCache{path: path, entries: map[string]cacheEntry{}}
+
+	if path == "" {
+		return c
+	}
+
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Debug("Failed to read plugin cache", slog.String("path", path), slog.String("err", err.Error()))
+		}
+		return c
+	}
+
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		slog.Debug("Ignoring invalid plugin cache", slog.String("path", path), slog.String("err", err.Error()))
+		c.entries = map[string]cacheEntry{}
+	}
+
+	return c
+}
+
+// Returns the cached entry for a plugin if it has not changed since it was cached
+func (c *DescriptionCache) get(path string, info fs.FileInfo) (cacheEntry, bool) {
+	e, ok := c.entries[path]
+
+	if !ok || !e.ModTime.Equal(info.ModTime()) || e.Size != info.Size() {
+		return cacheEntry{}, false
+	}
+
+	return e, true
+}
+
+func (c *DescriptionCache) set(path string, info fs.FileInfo, desc *PluginDescription, err error) {
+	e := cacheEntry{ModTime: info.ModTime(), Size: info.Size(), Desc: desc}
+
+	if err != nil {
+		e.Err = err.Error()
+	}
+
+	c.entries[path] = e
+	c.dirty = true
+}
+
+// Saves the cache if it has changed
+func (c *DescriptionCache) Save() error {
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+
+	b, err := json.Marshal(c.entries)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.path, b, 0o644); err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,429 @@
+// Package plugins provides support for external plugin routes
+//
+// A plugin is any executable named `evil-befall-<name>` found on PATH or in the plugins directory. It is registered
+// as the route `<name>`.
+//
+// # Protocol
+//
+// The mode a plugin is invoked in is set in the EVIL_BEFALL_PLUGIN_MODE environment variable. In all modes, a JSON
+// PluginRequest is written to the plugins stdin.
+//
+// - describe: the plugin must print a PluginDescription as JSON to stdout
+//
+// - complete: the plugin must print a PluginCompletionResponse as JSON to stdout. PluginRequest.Complete is set
+//
+// - exec: the plugin is executed with its args as `key=value` argv. Its stdout/stderr are passed through and a non-zero
+// exit code is treated as an error
+//
+// Plugins must honor dry-run mode: when StateSnapshot.DryRun is set, they must not send requests that may modify data
+// (anything but GET and HEAD requests), and should show what they would have sent instead
+//
+// Plugins that fail to describe themselves or declare a newer protocol version than ProtocolVersion are not
+// registered. Descriptions are cached by the path, modification time and size of the executable, so plugins are
+// only described again once they change
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+const (
+	// The prefix all plugin executables must have
+	PluginPrefix = "evil-befall-"
+
+	// The version of the plugin protocol
+	ProtocolVersion = 1
+
+	// The environment variable the plugin mode is passed in
+	ModeEnv = "EVIL_BEFALL_PLUGIN_MODE"
+
+	ModeDescribe = "describe"
+	ModeComplete = "complete"
+	ModeExec     = "exec"
+)
+
+// How long describe and complete calls may take before being killed
+var MetadataTimeout = 5 * time.Second
+
+// A JSON snapshot of the parts of the state a plugin may need
+type StateSnapshot struct {
+	InstanceURL   string `json:"instance_url"`
+	SessionToken  string `json:"session_token,omitempty"`
+	UserID        string `json:"user_id,omitempty"`
+	SelectedGuild string `json:"selected_guild,omitempty"`
+
+	// Whether dry-run mode is on, see the package documentation
+	DryRun bool `json:"dry_run"`
+}
+
+func NewStateSnapshot(state *state.State) StateSnapshot {
+	snapshot := StateSnapshot{
+		InstanceURL:   state.StateFetchOptions.InstanceAPIUrl,
+		SelectedGuild: state.SelectedOptions.GuildID,
+		DryRun:        state.StateFetchOptions.DryRun,
+	}
+
+	if sess, err := state.Session.GetCurrentSession(); err == nil {
+		snapshot.SessionToken = sess.Token
+		snapshot.UserID = sess.UserID
+	}
+
+	return snapshot
+}
+
+// The request written to a plugins stdin
+type PluginRequest struct {
+	Protocol int                `json:"protocol"`
+	Mode     string             `json:"mode"`
+	Args     map[string]string  `json:"args,omitempty"`
+	State    StateSnapshot      `json:"state"`
+	Complete *PluginCompleteReq `json:"complete,omitempty"`
+}
+
+// Sent in complete mode, the argument being completed and what the user has typed so far
+type PluginCompleteReq struct {
+	Arg     string `json:"arg"`
+	Partial string `json:"partial"`
+}
+
+// An argument declared by a plugin
+type PluginArgument struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Type        string   `json:"type,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	// Whether the plugin should be called in complete mode to complete this argument
+	Complete bool `json:"complete,omitempty"`
+}
+
+// The response of a plugin in describe mode
+type PluginDescription struct {
+	Protocol          int              `json:"protocol"`
+	Description       string           `json:"description"`
+	Arguments         []PluginArgument `json:"arguments"`
+	AllowsUnknownArgs bool             `json:"allows_unknown_args"`
+}
+
+// The response of a plugin in complete mode
+type PluginCompletionResponse struct {
+	Completions []string `json:"completions"`
+}
+
+// PluginRoute is a route backed by an external executable
+type PluginRoute struct {
+	Name string
+	Path string
+	Desc PluginDescription
+}
+
+func (r *PluginRoute) Command() string {
+	return r.Name
+}
+
+func (r *PluginRoute) Description() string {
+	if r.Desc.Description == "" {
+		return "External plugin (" + r.Path + ")"
+	}
+
+	return r.Desc.Description
+}
+
+func (r *PluginRoute) Arguments() []router.Argument {
+	var args = make([]router.Argument, 0, len(r.Desc.Arguments))
+
+	for _, pa := range r.Desc.Arguments {
+		arg := router.Argument{
+			Name:        pa.Name,
+			Description: pa.Description,
+			Type:        router.ArgType(pa.Type),
+			Required:    pa.Required,
+			Default:     pa.Default,
+			Enum:        pa.Enum,
+		}
+
+		if pa.Complete {
+			name := pa.Name
+			arg.Completion = func(state *state.State, partial string) ([]string, error) {
+				return r.complete(state, name, partial)
+			}
+		}
+
+		args = append(args, arg)
+	}
+
+	return args
+}
+
+func (r *PluginRoute) AllowsUnknownArgs() bool {
+	return r.Desc.AllowsUnknownArgs
+}
+
+func (r *PluginRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *PluginRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *PluginRoute) Render(state *state.State, args map[string]string) error {
+	var argv = make([]string, 0, len(args))
+
+	for k, v := range args {
+		argv = append(argv, k+"="+v)
+	}
+
+	slices.Sort(argv)
+
+	stdin, err := r.request(state, ModeExec, args, nil)
+
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(r.Path, argv...)
+	cmd.Env = append(os.Environ(), ModeEnv+"="+ModeExec)
+	cmd.Stdin = stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s failed: %w", r.Name, err)
+	}
+
+	return nil
+}
+
+func (r *PluginRoute) request(state *state.State, mode string, args map[string]string, complete *PluginCompleteReq) (*bytes.Reader, error) {
+	req := PluginRequest{
+		Protocol: ProtocolVersion,
+		Mode:     mode,
+		Args:     args,
+		Complete: complete,
+	}
+
+	if state != nil {
+		req.State = NewStateSnapshot(state)
+	}
+
+	b, err := json.Marshal(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(b), nil
+}
+
+// Runs the plugin in a metadata mode (describe/complete), decoding its stdout into v
+func (r *PluginRoute) call(state *state.State, mode string, complete *PluginCompleteReq, v any) error {
+	stdin, err := r.request(state, mode, nil, complete)
+
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MetadataTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, r.Path)
+	cmd.Env = append(os.Environ(), ModeEnv+"="+mode)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s failed in %s mode: %w [%s]", r.Name, mode, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("plugin %s returned invalid JSON in %s mode: %w", r.Name, mode, err)
+	}
+
+	return nil
+}
+
+func (r *PluginRoute) complete(state *state.State, arg, partial string) ([]string, error) {
+	var resp PluginCompletionResponse
+
+	if err := r.call(state, ModeComplete, &PluginCompleteReq{Arg: arg, Partial: partial}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Completions, nil
+}
+
+// Loads a plugin from an executable path, asking it to describe itself
+//
+// Plugins that fail to describe themselves or use a newer protocol version cannot be used, so an error is
+// returned for them
+func LoadPlugin(path string) (*PluginRoute, error) {
+	name := pluginName(filepath.Base(path))
+
+	if name == "" {
+		return nil, fmt.Errorf("%s is not a plugin", path)
+	}
+
+	r := &PluginRoute{
+		Name: name,
+		Path: path,
+	}
+
+	if err := r.call(nil, ModeDescribe, nil, &r.Desc); err != nil {
+		return nil, err
+	}
+
+	if err := checkProtocol(name, r.Desc); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func checkProtocol(name string, desc PluginDescription) error {
+	if desc.Protocol > ProtocolVersion {
+		return fmt.Errorf("plugin %s uses unsupported protocol version %d", name, desc.Protocol)
+	}
+
+	return nil
+}
+
+// Options for Discover
+type DiscoverOptions struct {
+	// Directories searched before PATH
+	Dirs []string
+
+	// Caches the descriptions of plugins. Plugins are only run in describe mode if they are not in it
+	Cache *DescriptionCache
+
+	// Never run plugins, only registering those with a cached description. Used when completing for system
+	// shells, which should not wait on arbitrary executables
+	CacheOnly bool
+}
+
+// Discovers all plugins in the given directories followed by PATH. If a plugin name is found
+// more than once, the first one wins
+//
+// Plugins that cannot be used (see LoadPlugin) are skipped
+func Discover(opts DiscoverOptions) []*PluginRoute {
+	dirs := append(slices.Clone(opts.Dirs), filepath.SplitList(os.Getenv("PATH"))...)
+
+	cache := opts.Cache
+
+	if cache == nil {
+		cache = LoadDescriptionCache("")
+	}
+
+	var found []*PluginRoute
+	var seen = map[string]bool{}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.Debug("Failed to read plugin directory", slog.String("dir", dir), slog.String("err", err.Error()))
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			name := pluginName(entry.Name())
+
+			if name == "" || seen[name] {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+
+			if err != nil || !isExecutable(path, info) {
+				continue
+			}
+
+			seen[name] = true
+
+			if e, ok := cache.get(path, info); ok {
+				if e.Err != "" || e.Desc == nil {
+					slog.Debug("Skipping plugin that cannot be used", slog.String("path", path), slog.String("err", e.Err))
+					continue
+				}
+
+				found = append(found, &PluginRoute{Name: name, Path: path, Desc: *e.Desc})
+				continue
+			}
+
+			if opts.CacheOnly {
+				continue
+			}
+
+			p, err := LoadPlugin(path)
+
+			if err != nil {
+				slog.Warn("Skipping plugin that cannot be used", slog.String("path", path), slog.String("err", err.Error()))
+				cache.set(path, info, nil, err)
+				continue
+			}
+
+			cache.set(path, info, &p.Desc, nil)
+			found = append(found, p)
+		}
+	}
+
+	if err := cache.Save(); err != nil {
+		slog.Debug("Failed to save plugin cache", slog.String("err", err.Error()))
+	}
+
+	return found
+}
+
+// Returns the route name for a plugin file name, or an empty string if it is not a plugin
+func pluginName(file string) string {
+	if !strings.HasPrefix(file, PluginPrefix) {
+		return ""
+	}
+
+	name := strings.TrimPrefix(file, PluginPrefix)
+
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	return name
+}
+
+func isExecutable(path string, info fs.FileInfo) bool {
+	if info.IsDir() {
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+
+	return info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,183 @@
+package plugins
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// A fake plugin answering each mode of the protocol. It records its stdin and argv in the directory given by
+// PLUGIN_OUT, and every describe call in PLUGIN_OUT/describes
+const fakePlugin = `#!/bin/sh
+cat > "$PLUGIN_OUT/stdin"
+case "$EVIL_BEFALL_PLUGIN_MODE" in
+describe)
+	echo x >> "$PLUGIN_OUT/describes"
+	echo '{"protocol": %PROTOCOL%, "description": "A fake plugin", "arguments": [{"name": "target", "description": "The target", "required": true, "complete": true}]}'
+	;;
+complete)
+	echo '{"completions": ["alpha", "beta"]}'
+	;;
+exec)
+	echo "$@" > "$PLUGIN_OUT/argv"
+	;;
+esac
+`
+
+const brokenPlugin = `#!/bin/sh
+echo x >> "$PLUGIN_OUT/describes"
+echo "cannot describe" >&2
+exit 1
+`
+
+func writePlugin(t *testing.T, dir, name, script string) string {
+	path := filepath.Join(dir, PluginPrefix+name)
+	assert.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func fakePluginScript(protocol string) string {
+	return strings.ReplaceAll(fakePlugin, "%PROTOCOL%", protocol)
+}
+
+func setup(t *testing.T) (dir, out string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	cat, err := exec.LookPath("cat")
+	assert.NoError(t, err)
+
+	// PATH only has what the fake plugins need, so plugins installed on it are not discovered
+	dir, out = t.TempDir(), t.TempDir()
+	t.Setenv("PLUGIN_OUT", out)
+	t.Setenv("PATH", filepath.Dir(cat))
+	return dir, out
+}
+
+func readRequest(t *testing.T, out string) PluginRequest {
+	b, err := os.ReadFile(filepath.Join(out, "stdin"))
+	assert.NoError(t, err)
+
+	var req PluginRequest
+	assert.NoError(t, json.Unmarshal(b, &req))
+	return req
+}
+
+func describes(out string) int {
+	b, _ := os.ReadFile(filepath.Join(out, "describes"))
+	return strings.Count(string(b), "x")
+}
+
+func TestLoadPlugin(t *testing.T) {
+	dir, out := setup(t)
+
+	p, err := LoadPlugin(writePlugin(t, dir, "fake", fakePluginScript("1")))
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", p.Command())
+	assert.Equal(t, "A fake plugin", p.Description())
+	assert.False(t, p.AllowsUnknownArgs())
+
+	args := p.Arguments()
+	assert.Len(t, args, 1)
+	assert.Equal(t, "target", args[0].Name)
+	assert.True(t, args[0].Required)
+	assert.Equal(t, ModeDescribe, readRequest(t, out).Mode)
+
+	// Plugins speaking a newer protocol or failing to describe themselves cannot be used
+	p, err = LoadPlugin(writePlugin(t, dir, "future", fakePluginScript("2")))
+	assert.ErrorContains(t, err, "unsupported protocol version 2")
+	assert.Nil(t, p)
+
+	p, err = LoadPlugin(writePlugin(t, dir, "broken", brokenPlugin))
+	assert.ErrorContains(t, err, "cannot describe")
+	assert.Nil(t, p)
+}
+
+func TestPluginComplete(t *testing.T) {
+	dir, out := setup(t)
+
+	p, err := LoadPlugin(writePlugin(t, dir, "fake", fakePluginScript("1")))
+	assert.NoError(t, err)
+
+	s := &state.State{}
+	s.SelectedOptions.GuildID = "42"
+
+	completions, err := p.Arguments()[0].Completion(s, "al")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, completions)
+
+	req := readRequest(t, out)
+	assert.Equal(t, ModeComplete, req.Mode)
+	assert.Equal(t, &PluginCompleteReq{Arg: "target", Partial: "al"}, req.Complete)
+	assert.Equal(t, "42", req.State.SelectedGuild)
+}
+
+func TestPluginExec(t *testing.T) {
+	dir, out := setup(t)
+
+	p, err := LoadPlugin(writePlugin(t, dir, "fake", fakePluginScript("1")))
+	assert.NoError(t, err)
+
+	s := &state.State{}
+	s.StateFetchOptions.InstanceAPIUrl = "http://localhost:1234"
+	s.StateFetchOptions.DryRun = true
+
+	assert.NoError(t, p.Render(s, map[string]string{"target": "b", "count": "2"}))
+
+	argv, err := os.ReadFile(filepath.Join(out, "argv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "count=2 target=b\n", string(argv))
+
+	req := readRequest(t, out)
+	assert.Equal(t, ModeExec, req.Mode)
+	assert.Equal(t, map[string]string{"target": "b", "count": "2"}, req.Args)
+	assert.Equal(t, "http://localhost:1234", req.State.InstanceURL)
+	assert.True(t, req.State.DryRun)
+}
+
+func TestDiscover(t *testing.T) {
+	dir, out := setup(t)
+
+	writePlugin(t, dir, "fake", fakePluginScript("1"))
+	writePlugin(t, dir, "broken", brokenPlugin)
+	writePlugin(t, dir, "future", fakePluginScript("2"))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, PluginPrefix+"data"), []byte("not executable"), 0o644))
+
+	cachePath := filepath.Join(t.TempDir(), "plugins.json")
+
+	names := func(found []*PluginRoute) []string {
+		var n []string
+		for _, p := range found {
+			n = append(n, p.Command())
+		}
+		return n
+	}
+
+	// Nothing is cached yet, so completing for system shells finds no plugins
+	assert.Empty(t, Discover(DiscoverOptions{Dirs: []string{dir}, Cache: LoadDescriptionCache(cachePath), CacheOnly: true}))
+	assert.Equal(t, 0, describes(out))
+
+	found := Discover(DiscoverOptions{Dirs: []string{dir}, Cache: LoadDescriptionCache(cachePath)})
+	assert.Equal(t, []string{"fake"}, names(found))
+	assert.Equal(t, 3, describes(out))
+
+	// Descriptions and failures are cached, so no plugin is run again until it changes
+	found = Discover(DiscoverOptions{Dirs: []string{dir}, Cache: LoadDescriptionCache(cachePath)})
+	assert.Equal(t, []string{"fake"}, names(found))
+	assert.Equal(t, "A fake plugin", found[0].Description())
+	assert.Equal(t, 3, describes(out))
+
+	writePlugin(t, dir, "broken", fakePluginScript("1")+"\n# fixed\n")
+
+	found = Discover(DiscoverOptions{Dirs: []string{dir}, Cache: LoadDescriptionCache(cachePath)})
+	assert.Equal(t, []string{"broken", "fake"}, names(found))
+	assert.Equal(t, 4, describes(out))
+}
@@ -0,0 +1,307 @@
+// Package prompt renders the interactive shell prompt from a user-configurable template
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/api/users"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+// The default prompt template. The first line is colored, the last line is the actual input prompt
+const DefaultTemplate = "[{{.Host}}]{{if .DryRun}} [dry run]{{end}} {{.User}} @ {{.Guild}}{{if .Remaining}} ({{.Remaining}} left){{end}}\nevil-befall> "
+
+// How long to wait for user/guild lookups before falling back to IDs
+var LookupTimeout = 3 * time.Second
+
+// How long failed user/guild lookups are remembered before being retried. Failures are remembered so an
+// unreachable instance does not slow down every prompt, but not forever so a transient error does not hide names
+// until restart
+var FailedLookupTTL = time.Minute
+
+// The data available to prompt templates
+type Data struct {
+	// The host of the instance, e.g. splashtail-staging.antiraid.xyz
+	Host string
+
+	// Whether the instance is a production instance
+	Production bool
+
+	// Whether dry-run mode is on
+	DryRun bool
+
+	// The username of the current session user, or their ID if it could not be resolved
+	User   string
+	UserID string
+
+	// The name of the selected guild, or its ID if it could not be resolved
+	Guild   string
+	GuildID string
+
+	// Time remaining on the current session, empty if there is no session
+	Remaining string
+}
+
+// Prompter renders prompts, caching user and guild name lookups
+type Prompter struct {
+	tmpl *template.Template
+
+	mu     sync.Mutex
+	users  map[string]lookup[string]
+	guilds map[string]lookup[map[string]string]
+}
+
+// The result of a user/guild lookup
+type lookup[T any] struct {
+	value T
+
+	// When the lookup failed, zero if it succeeded
+	failedAt time.Time
+}
+
+// Returns whether a cached lookup can still be used
+func (l lookup[T]) fresh() bool {
+	return l.failedAt.IsZero() || time.Since(l.failedAt) < FailedLookupTTL
+}
+
+// Creates a new Prompter from a text/template. A literal `\n` in the template is treated as a newline
+// so templates can be passed in through environment variables
+func New(tmpl string) (*Prompter, error) {
+	t, err := template.New("prompt").Parse(strings.ReplaceAll(tmpl, `\n`, "\n"))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	return &Prompter{
+		tmpl:   t,
+		users:  map[string]lookup[string]{},
+		guilds: map[string]lookup[map[string]string]{},
+	}, nil
+}
+
+// Returns whether an instance URL points to a production instance
+//
+// Any instance that is not local or a staging/dev instance is considered production
+func IsProductionInstance(instanceUrl string) bool {
+	if instanceUrl == "" {
+		return false
+	}
+
+	u, err := url.Parse(instanceUrl)
+
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+
+	if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+		return false
+	}
+
+	for _, marker := range []string{"staging", "dev", "test"} {
+		if strings.Contains(host, marker) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Collects the prompt data from the state
+func (p *Prompter) Data(s *state.State) Data {
+	d := Data{
+		Host:       "no instance",
+		Production: IsProductionInstance(s.StateFetchOptions.InstanceAPIUrl),
+		DryRun:     s.StateFetchOptions.DryRun,
+		User:       "not logged in",
+		Guild:      "no guild",
+		GuildID:    s.SelectedOptions.GuildID,
+	}
+
+	if u, err := url.Parse(s.StateFetchOptions.InstanceAPIUrl); err == nil && u.Host != "" {
+		d.Host = u.Host
+	}
+
+	sess := s.Session.PeekCurrentSession()
+
+	if sess != nil {
+		d.UserID = sess.UserID
+		d.User = p.userName(s, sess.UserID)
+
+		remaining := time.Until(sess.Expiry)
+
+		if remaining <= 0 {
+			d.Remaining = "expired"
+		} else {
+			d.Remaining = formatRemaining(remaining)
+		}
+	}
+
+	if d.GuildID != "" {
+		d.Guild = d.GuildID
+
+		if sess != nil {
+			d.Guild = p.guildName(s, sess.UserID, d.GuildID)
+		}
+	}
+
+	return d
+}
+
+// Renders the prompt
+//
+// The line editor cannot handle escape codes in the prompt, so all but the last line of the rendered
+// template are printed here (colored red on production instances, yellow in dry-run mode) and the last line is returned
+func (p *Prompter) Render(s *state.State) string {
+	info, prompt, err := p.render(p.Data(s))
+
+	if err != nil {
+		slog.Error("Failed to render prompt", slog.String("err", err.Error()))
+		return "evil-befall> "
+	}
+
+	if info != "" {
+		fmt.Println(info)
+	}
+
+	return prompt
+}
+
+// Renders the template, returning the colored line(s) to print above the input line, if any, and the input line
+//
+// Templates rendering to a single line have nothing to color, so on production instances and in dry-run mode a
+// line saying so is printed above them as these must stand out whatever the template
+func (p *Prompter) render(d Data) (info, prompt string, err error) {
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, d); err != nil {
+		return "", "", err
+	}
+
+	rendered := sb.String()
+
+	color := ansi.Green
+
+	switch {
+	case d.DryRun:
+		color = ansi.Yellow
+	case d.Production:
+		color = ansi.BoldRed
+	}
+
+	idx := strings.LastIndex(rendered, "\n")
+
+	if idx != -1 {
+		return ansi.Color(color, rendered[:idx]), rendered[idx+1:], nil
+	}
+
+	var notes []string
+
+	if d.Production {
+		notes = append(notes, "production instance")
+	}
+
+	if d.DryRun {
+		notes = append(notes, "dry run")
+	}
+
+	if len(notes) == 0 {
+		return "", rendered, nil
+	}
+
+	return ansi.Color(color, "["+d.Host+"] "+strings.Join(notes, ", ")), rendered, nil
+}
+
+func (p *Prompter) userName(s *state.State, userID string) string {
+	key := s.StateFetchOptions.InstanceAPIUrl + "/" + userID
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if l, ok := p.users[key]; ok && l.fresh() {
+		return l.value
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), LookupTimeout)
+	defer cancel()
+
+	user, err := users.GetUser(ctx, s, &users.GetUserData{ID: userID})
+
+	if err != nil {
+		slog.Debug("Failed to resolve user for prompt", slog.String("err", err.Error()))
+		p.users[key] = lookup[string]{value: userID, failedAt: time.Now()}
+		return userID
+	}
+
+	name := userID
+
+	if user.User != nil && user.User.Username != "" {
+		name = user.User.Username
+	}
+
+	p.users[key] = lookup[string]{value: name}
+
+	return name
+}
+
+func (p *Prompter) guildName(s *state.State, userID, guildID string) string {
+	key := s.StateFetchOptions.InstanceAPIUrl + "/" + userID
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l, ok := p.guilds[key]
+
+	if !ok || !l.fresh() {
+		l = lookup[map[string]string]{value: map[string]string{}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), LookupTimeout)
+		defer cancel()
+
+		guilds, err := users.GetUserGuilds(ctx, s, &users.GetUserGuildsData{})
+
+		if err != nil {
+			slog.Debug("Failed to resolve guilds for prompt", slog.String("err", err.Error()))
+			l.failedAt = time.Now()
+		} else {
+			for _, g := range guilds.Guilds {
+				l.value[g.ID] = g.Name
+			}
+		}
+
+		p.guilds[key] = l
+	}
+
+	if name, ok := l.value[guildID]; ok {
+		return name
+	}
+
+	return guildID
+}
+
+// Formats a duration as e.g. 2h5m, dropping seconds
+func formatRemaining(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+
+	d = d.Truncate(time.Minute)
+
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+
+	return fmt.Sprintf("%dh%dm", h, m)
+}
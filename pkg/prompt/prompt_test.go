@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProductionInstance(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"", false},
+		{"http://localhost:3010", false},
+		{"http://127.0.0.1:3010", false},
+		{"http://[::1]:3010", false},
+		{"https://splashtail-staging.antiraid.xyz", false},
+		{"https://dev.antiraid.xyz", false},
+		{"https://test.antiraid.xyz", false},
+		{"https://splashtail.antiraid.xyz", true},
+		{"://invalid", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsProductionInstance(tt.url), tt.url)
+	}
+}
+
+func TestFormatRemaining(t *testing.T) {
+	assert.Equal(t, "<1m", formatRemaining(30*time.Second))
+	assert.Equal(t, "5m", formatRemaining(5*time.Minute+30*time.Second))
+	assert.Equal(t, "2h3m", formatRemaining(2*time.Hour+3*time.Minute+59*time.Second))
+	assert.Equal(t, "24h0m", formatRemaining(24*time.Hour))
+}
+
+func TestData(t *testing.T) {
+	p, err := New(DefaultTemplate)
+	assert.NoError(t, err)
+
+	s := &state.State{}
+	d := p.Data(s)
+	assert.Equal(t, "no instance", d.Host)
+	assert.Equal(t, "not logged in", d.User)
+	assert.Equal(t, "no guild", d.Guild)
+	assert.Empty(t, d.Remaining)
+
+	// Without a session, guild names cannot be looked up so the ID is shown
+	s.StateFetchOptions.InstanceAPIUrl = "https://splashtail.antiraid.xyz"
+	s.StateFetchOptions.DryRun = true
+	s.SelectedOptions.GuildID = "42"
+
+	d = p.Data(s)
+	assert.Equal(t, "splashtail.antiraid.xyz", d.Host)
+	assert.True(t, d.Production)
+	assert.True(t, d.DryRun)
+	assert.Equal(t, "42", d.Guild)
+	assert.Equal(t, "42", d.GuildID)
+}
+
+func TestRender(t *testing.T) {
+	p, err := New(`{{.Host}} {{.User}} @ {{.Guild}}{{if .Remaining}} ({{.Remaining}} left){{end}}\n> `)
+	assert.NoError(t, err)
+
+	info, prompt, err := p.render(Data{Host: "localhost:3010", User: "alice", Guild: "Guild", Remaining: "5m"})
+	assert.NoError(t, err)
+	assert.Equal(t, ansi.Color(ansi.Green, "localhost:3010 alice @ Guild (5m left)"), info)
+	assert.Equal(t, "> ", prompt)
+
+	info, _, err = p.render(Data{Host: "splashtail.antiraid.xyz", Production: true, User: "alice", Guild: "Guild"})
+	assert.NoError(t, err)
+	assert.Equal(t, ansi.Color(ansi.BoldRed, "splashtail.antiraid.xyz alice @ Guild"), info)
+
+	// Single line templates have nothing to color, so only production instances and dry-run mode print a line
+	p, err = New("{{.User}}> ")
+	assert.NoError(t, err)
+
+	info, prompt, err = p.render(Data{Host: "localhost:3010", User: "alice"})
+	assert.NoError(t, err)
+	assert.Empty(t, info)
+	assert.Equal(t, "alice> ", prompt)
+
+	info, prompt, err = p.render(Data{Host: "splashtail.antiraid.xyz", Production: true, DryRun: true, User: "alice"})
+	assert.NoError(t, err)
+	assert.Contains(t, info, "[splashtail.antiraid.xyz] production instance, dry run")
+	assert.Equal(t, "alice> ", prompt)
+
+	p, err = New("{{.Missing}}")
+	assert.NoError(t, err)
+
+	_, _, err = p.render(Data{})
+	assert.Error(t, err)
+}
+
+func TestLookupFresh(t *testing.T) {
+	assert.True(t, lookup[string]{value: "alice"}.fresh())
+	assert.True(t, lookup[string]{failedAt: time.Now()}.fresh())
+	assert.False(t, lookup[string]{failedAt: time.Now().Add(-FailedLookupTTL)}.fresh())
+}
@@ -0,0 +1,303 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+var (
+	ErrMissingArgument = errors.New("missing required argument")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrUnknownArgument = errors.New("unknown argument")
+)
+
+// DryRunArgument is accepted by every route. If true, requests that are not GET requests are shown instead of
+// being sent while the route runs, see state.StateFetchOptions.DryRun
+var DryRunArgument = Argument{
+	Name:        "__dry_run",
+	Description: "Show requests that would modify data instead of sending them",
+	Type:        ArgTypeBool,
+}
+
+// The type of an argument, used to validate and coerce the raw string value
+type ArgType string
+
+const (
+	ArgTypeString ArgType = "string"
+	ArgTypeBool   ArgType = "bool"
+	ArgTypeInt    ArgType = "int"
+	ArgTypeFloat  ArgType = "float"
+
+	// A Go duration (e.g. 5s, 1m30s). A plain number is treated as seconds
+	ArgTypeDuration ArgType = "duration"
+)
+
+// A completion source returns the candidate values for an argument given the partial value the user has typed
+type CompletionSource func(state *state.State, partial string) ([]string, error)
+
+// Argument describes a single argument a route can take
+type Argument struct {
+	// The name of the argument
+	Name string
+
+	// The description of the argument
+	Description string
+
+	// The type of the argument. Defaults to ArgTypeString if unset
+	Type ArgType
+
+	// Whether or not the argument must be provided (or defaulted)
+	Required bool
+
+	// The static default value of the argument, used if the argument is not provided
+	Default string
+
+	// A default computed from the state (e.g. the selected guild). Takes precedence over Default
+	DefaultFunc func(state *state.State) string
+
+	// Human readable form of DefaultFunc to show in help, e.g. "[selected guild id]"
+	DefaultHelp string
+
+	// If set, the value must be one of these
+	Enum []string
+
+	// Where to get completions for the argument's value from. If unset, Enum is used
+	Completion CompletionSource
+}
+
+// Returns the type of the argument, defaulting to string
+func (a Argument) ArgType() ArgType {
+	if a.Type == "" {
+		return ArgTypeString
+	}
+
+	return a.Type
+}
+
+// Returns the default value to show in help
+func (a Argument) DefaultString() string {
+	if a.DefaultHelp != "" {
+		return a.DefaultHelp
+	}
+
+	return a.Default
+}
+
+// Coerces a raw value into the canonical string form for the arguments type
+func (a Argument) Coerce(value string) (string, error) {
+	switch a.ArgType() {
+	case ArgTypeBool:
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "", "true", "t", "1", "yes", "y", "on":
+			// An empty bool is treated as a flag that has been set
+			value = "true"
+		case "false", "f", "0", "no", "n", "off":
+			value = "false"
+		default:
+			return "", fmt.Errorf("%w: %s=%s is not a bool", ErrInvalidArgument, a.Name, value)
+		}
+	case ArgTypeInt:
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+
+		if err != nil {
+			return "", fmt.Errorf("%w: %s=%s is not an integer", ErrInvalidArgument, a.Name, value)
+		}
+
+		value = strconv.FormatInt(i, 10)
+	case ArgTypeFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+
+		if err != nil {
+			return "", fmt.Errorf("%w: %s=%s is not a number", ErrInvalidArgument, a.Name, value)
+		}
+
+		value = strconv.FormatFloat(f, 'f', -1, 64)
+	case ArgTypeDuration:
+		value = strings.TrimSpace(value)
+
+		if secs, err := strconv.ParseFloat(value, 64); err == nil {
+			value = time.Duration(secs * float64(time.Second)).String()
+		}
+
+		d, err := time.ParseDuration(value)
+
+		if err != nil {
+			return "", fmt.Errorf("%w: %s=%s is not a duration", ErrInvalidArgument, a.Name, value)
+		}
+
+		value = d.String()
+	}
+
+	if len(a.Enum) > 0 && !slices.Contains(a.Enum, value) {
+		return "", fmt.Errorf("%w: %s must be one of %s, got %s", ErrInvalidArgument, a.Name, strings.Join(a.Enum, ", "), value)
+	}
+
+	return value, nil
+}
+
+// Returns the candidate values for the argument
+func (a Argument) Candidates(state *state.State, partial string) ([]string, error) {
+	var candidates []string
+
+	if a.Completion != nil {
+		c, err := a.Completion(state, partial)
+
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = c
+	} else if len(a.Enum) > 0 {
+		candidates = a.Enum
+	} else if a.ArgType() == ArgTypeBool {
+		candidates = []string{"true", "false"}
+	}
+
+	var filtered []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(partial)) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered, nil
+}
+
+// A DefaultFunc returning the currently selected guild
+func SelectedGuildDefault(state *state.State) string {
+	return state.SelectedOptions.GuildID
+}
+
+// Routes implementing this interface accept arguments beyond those declared in Arguments (e.g. request fields)
+type FreeformArgsRoute interface {
+	AllowsUnknownArgs() bool
+}
+
+// Validates and coerces args against the routes argument spec, filling in defaults
+//
+// The returned map is a copy, the passed args are not modified
+func ValidateArgs(r Route, state *state.State, args map[string]string) (map[string]string, error) {
+	spec := r.Arguments()
+
+	allowUnknown := false
+	if fr, ok := r.(FreeformArgsRoute); ok {
+		allowUnknown = fr.AllowsUnknownArgs()
+	}
+
+	var coerced = make(map[string]string, len(args))
+
+	for k, v := range args {
+		idx := slices.IndexFunc(spec, func(a Argument) bool { return a.Name == k })
+
+		if idx == -1 {
+			if !allowUnknown {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownArgument, k)
+			}
+
+			coerced[k] = v
+			continue
+		}
+
+		val, err := spec[idx].Coerce(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		coerced[k] = val
+	}
+
+	for _, a := range spec {
+		if _, ok := coerced[a.Name]; ok {
+			continue
+		}
+
+		var def string
+		if a.DefaultFunc != nil && state != nil {
+			def = a.DefaultFunc(state)
+		}
+
+		if def == "" {
+			def = a.Default
+		}
+
+		if def != "" {
+			val, err := a.Coerce(def)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid default for %s: %w", a.Name, err)
+			}
+
+			coerced[a.Name] = val
+			continue
+		}
+
+		if a.Required {
+			return nil, fmt.Errorf("%w: %s", ErrMissingArgument, a.Name)
+		}
+	}
+
+	return coerced, nil
+}
+
+// Converts an argument spec to the [name, description, default] triples the shell expects
+func ShellArgs(spec []Argument) [][3]string {
+	var args = make([][3]string, 0, len(spec))
+
+	for _, a := range spec {
+		args = append(args, [3]string{a.Name, a.Description, a.DefaultString()})
+	}
+
+	return args
+}
+
+// Returns the help text for a route
+func Help(r Route) string {
+	var sb strings.Builder
+
+	sb.WriteString("Command: " + r.Command() + "\n")
+	sb.WriteString("Description: " + r.Description() + "\n")
+
+	spec := r.Arguments()
+
+	if len(spec) == 0 {
+		sb.WriteString("Arguments: none\n")
+		return sb.String()
+	}
+
+	sb.WriteString("Arguments:\n")
+
+	for _, a := range spec {
+		sb.WriteString(fmt.Sprintf("  %s (%s", a.Name, a.ArgType()))
+
+		if a.Required {
+			sb.WriteString(", required")
+		}
+
+		if def := a.DefaultString(); def != "" {
+			sb.WriteString(", default: " + def)
+		}
+
+		sb.WriteString("): " + a.Description)
+
+		if len(a.Enum) > 0 {
+			sb.WriteString(" [" + strings.Join(a.Enum, "|") + "]")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	if fr, ok := r.(FreeformArgsRoute); ok && fr.AllowsUnknownArgs() {
+		sb.WriteString("  ... (additional arguments are accepted)\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("  %s (%s): %s\n", DryRunArgument.Name, DryRunArgument.ArgType(), DryRunArgument.Description))
+
+	return sb.String()
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRoute struct {
+	args     []Argument
+	freeform bool
+}
+
+func (r *testRoute) Command() string                                         { return "test" }
+func (r *testRoute) Description() string                                     { return "A test route" }
+func (r *testRoute) Arguments() []Argument                                   { return r.args }
+func (r *testRoute) Setup(state *state.State) error                          { return nil }
+func (r *testRoute) Destroy(state *state.State) error                        { return nil }
+func (r *testRoute) Render(state *state.State, args map[string]string) error { return nil }
+func (r *testRoute) AllowsUnknownArgs() bool                                 { return r.freeform }
+
+func TestValidateArgs(t *testing.T) {
+	r := &testRoute{
+		args: []Argument{
+			{Name: "guildId", Required: true, DefaultFunc: SelectedGuildDefault},
+			{Name: "refresh", Type: ArgTypeBool, Default: "false"},
+			{Name: "count", Type: ArgTypeInt},
+			{Name: "mode", Enum: []string{"json", "spew"}},
+		},
+	}
+
+	s := &state.State{}
+
+	_, err := ValidateArgs(r, s, map[string]string{})
+	assert.ErrorIs(t, err, ErrMissingArgument)
+
+	s.SelectedOptions.GuildID = "123"
+
+	args, err := ValidateArgs(r, s, map[string]string{"refresh": "yes", "count": " 5 "})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"guildId": "123", "refresh": "true", "count": "5"}, args)
+
+	_, err = ValidateArgs(r, s, map[string]string{"count": "abc"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = ValidateArgs(r, s, map[string]string{"mode": "xml"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = ValidateArgs(r, s, map[string]string{"foo": "bar"})
+	assert.ErrorIs(t, err, ErrUnknownArgument)
+
+	r.freeform = true
+
+	args, err = ValidateArgs(r, s, map[string]string{"foo::json": "{}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", args["foo::json"])
+}
+
+func TestTakeDryRun(t *testing.T) {
+	args, dryRun, err := takeDryRun(map[string]string{"a": "b", "__dry_run": "yes"})
+	assert.NoError(t, err)
+	assert.True(t, dryRun)
+	assert.Equal(t, map[string]string{"a": "b"}, args)
+
+	args, dryRun, err = takeDryRun(map[string]string{"a": "b"})
+	assert.NoError(t, err)
+	assert.False(t, dryRun)
+	assert.Equal(t, map[string]string{"a": "b"}, args)
+
+	_, _, err = takeDryRun(map[string]string{"__dry_run": "maybe"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
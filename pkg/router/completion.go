@@ -0,0 +1,140 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/shellcli/shell"
+	"github.com/anti-raid/spintrack/strutils"
+)
+
+// Candidates may carry a description after DescriptionSep (e.g. the name of a channel whose ID is being
+// completed). Descriptions are shown next to candidates but never inserted
+const DescriptionSep = "\t"
+
+// Returns a candidate with a description
+func Describe(candidate, description string) string {
+	if description == "" {
+		return candidate
+	}
+
+	return candidate + DescriptionSep + description
+}
+
+// Splits a candidate into the text to insert and its description
+func SplitDescription(candidate string) (string, string) {
+	text, description, _ := strings.Cut(candidate, DescriptionSep)
+	return text, description
+}
+
+// Prepares candidates for the interactive shell, which inserts a lone candidate and otherwise lists them and
+// inserts their common prefix. Lone candidates lose their description, listed ones show it in brackets
+func InteractiveCandidates(candidates []string) []string {
+	var c = make([]string, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		text, description := SplitDescription(candidate)
+
+		if description != "" && len(candidates) > 1 {
+			text += "  (" + description + ")"
+		}
+
+		c = append(c, text)
+	}
+
+	return c
+}
+
+// Returns the name of the argument whose value is being typed at the end of a line and the partial value typed
+// so far, if any
+func TypingValue(line string) (string, string, bool) {
+	if strings.HasSuffix(line, " ") {
+		return "", "", false
+	}
+
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 {
+		return "", "", false
+	}
+
+	return strings.Cut(fields[len(fields)-1], "=")
+}
+
+// Returns the name=value arguments of a line. Completers reading other arguments should use this rather than the
+// args the shell passes them, as the shell treats an argument with an empty value (such as one whose value is about
+// to be typed) as a positional argument
+func LineArgs(line string) map[string]string {
+	args := map[string]string{}
+
+	for _, field := range strings.Fields(line) {
+		if name, value, ok := strings.Cut(field, "="); ok {
+			args[name] = value
+		}
+	}
+
+	return args
+}
+
+// Returns completions of a line setting the value being typed for an argument to each candidate
+func ValueCompletions(line, name string, candidates []string) []string {
+	fields := strings.Fields(line)
+	base := strings.TrimSpace(strings.TrimSuffix(line, fields[len(fields)-1]))
+
+	var c []string
+	for _, candidate := range candidates {
+		c = append(c, base+" "+name+"="+candidate)
+	}
+
+	return c
+}
+
+// Completes a line for a route using its argument spec
+//
+// If the user is typing a value (name=partial), candidate values are returned. Otherwise, argument names
+// that have not yet been set are returned
+func CompleteArgs(r Route, state *state.State, line string, args map[string]string) ([]string, error) {
+	spec := append(r.Arguments(), DryRunArgument)
+
+	argsStr := strings.Replace(line, r.Command(), "", 1)
+
+	// Case 1: the user is typing out a value
+	if name, partial, ok := TypingValue(line); ok {
+		for _, a := range spec {
+			if a.Name != name {
+				continue
+			}
+
+			candidates, err := a.Candidates(state, partial)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return ValueCompletions(line, name, candidates), nil
+		}
+
+		return nil, nil
+	}
+
+	// Case 2: the user is typing out an argument name
+	untypedArg := shell.UtilFindUntypedArgInArgStr(argsStr)
+
+	var c []string
+	for _, a := range spec {
+		if untypedArg != "" {
+			if strings.HasPrefix(a.Name, untypedArg) {
+				c = append(c, strings.TrimSpace(strutils.ReplaceFromBack(line, untypedArg, "", 1))+" "+a.Name+"=")
+			}
+			continue
+		}
+
+		if _, ok := args[a.Name]; ok {
+			continue // Skip if already set
+		}
+
+		c = append(c, strings.TrimSpace(line)+" "+a.Name+"=")
+	}
+
+	return c, nil
+}
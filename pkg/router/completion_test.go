@@ -0,0 +1,38 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypingValue(t *testing.T) {
+	name, partial, ok := TypingValue("test a=b value=gen")
+	assert.True(t, ok)
+	assert.Equal(t, "value", name)
+	assert.Equal(t, "gen", partial)
+
+	_, _, ok = TypingValue("test a=b ")
+	assert.False(t, ok)
+
+	_, _, ok = TypingValue("test val")
+	assert.False(t, ok)
+}
+
+func TestValueCompletions(t *testing.T) {
+	assert.Equal(t, []string{"test a=b value=1", "test a=b value=2"}, ValueCompletions("test a=b value=x", "value", []string{"1", "2"}))
+}
+
+func TestLineArgs(t *testing.T) {
+	// Empty values are kept rather than treated as positional arguments
+	assert.Equal(t, map[string]string{"pkey": "id", "pkeyValue": ""}, LineArgs("test pkey=id pkeyValue="))
+}
+
+func TestInteractiveCandidates(t *testing.T) {
+	candidates := []string{Describe("x=1", "general"), Describe("x=2", ""), Describe("x=3", "rules")}
+
+	assert.Equal(t, []string{"x=1  (general)", "x=2", "x=3  (rules)"}, InteractiveCandidates(candidates))
+
+	// A lone candidate is inserted, so it has no description
+	assert.Equal(t, []string{"x=1"}, InteractiveCandidates(candidates[:1]))
+}
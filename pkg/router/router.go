@@ -4,6 +4,8 @@ package router
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/anti-raid/evil-befall/pkg/state"
 )
@@ -59,8 +61,59 @@ func GotoCurrent(state *state.State, args map[string]string) (Route, error) {
 }
 
 func Goto(id string, state *state.State, args map[string]string) error {
+	return gotoRoute(id, state, args, func(r Route, args map[string]string) error {
+		return r.Render(state, args)
+	})
+}
+
+// GotoData goes to a route like Goto, but returns the result of the route as data instead of printing it
+//
+// Routes implementing DataRoute return their result directly. For all other routes, the output of Render is
+// captured and returned as a string
+func GotoData(id string, state *state.State, args map[string]string) (any, error) {
+	var result any
+
+	err := gotoRoute(id, state, args, func(r Route, args map[string]string) error {
+		if dr, ok := r.(DataRoute); ok {
+			res, err := dr.RenderData(state, args)
+			result = res
+			return err
+		}
+
+		out, err := captureStdout(func() error {
+			return r.Render(state, args)
+		})
+
+		result = out
+		return err
+	})
+
+	return result, err
+}
+
+func gotoRoute(id string, state *state.State, args map[string]string, render func(r Route, args map[string]string) error) error {
+	args, dryRun, err := takeDryRun(args)
+
+	if err != nil {
+		return err
+	}
+
+	r := GetRoute(id)
+
+	if r == nil {
+		return ErrRouteNotFound
+	}
+
+	// Arguments are validated before anything changes, so an invalid argument leaves the current location
+	// and route alone
+	args, err = ValidateArgs(r, state, args)
+
+	if err != nil {
+		return err
+	}
+
 	// Persist state if persist mode is enabled
-	err := state.PersistToDisk()
+	err = state.PersistToDisk()
 
 	if err != nil {
 		return fmt.Errorf("failed to persist state to disk: %w", err)
@@ -80,17 +133,18 @@ func Goto(id string, state *state.State, args map[string]string) error {
 		}
 	}
 
-	r := GetRoute(id)
-
-	if r == nil {
-		return ErrRouteNotFound
-	}
-
 	if err := r.Setup(state); err != nil {
 		return err
 	}
 
-	err = r.Render(state, args)
+	// Per-command dry runs are undone before persisting so they do not stick
+	if dryRun && !state.StateFetchOptions.DryRun {
+		state.StateFetchOptions.DryRun = true
+		err = render(r, args)
+		state.StateFetchOptions.DryRun = false
+	} else {
+		err = render(r, args)
+	}
 
 	if err != nil {
 		return err
@@ -106,6 +160,30 @@ func Goto(id string, state *state.State, args map[string]string) error {
 	return nil
 }
 
+// Removes the global __dry_run argument from args, returning whether it was set
+func takeDryRun(args map[string]string) (map[string]string, bool, error) {
+	v, ok := args[DryRunArgument.Name]
+
+	if !ok {
+		return args, false, nil
+	}
+
+	v, err := DryRunArgument.Coerce(v)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	rest := make(map[string]string, len(args)-1)
+	for k, val := range args {
+		if k != DryRunArgument.Name {
+			rest[k] = val
+		}
+	}
+
+	return rest, v == "true", nil
+}
+
 type Route interface {
 	// The command name of the route
 	Command() string
@@ -113,9 +191,8 @@ type Route interface {
 	// The description of the route
 	Description() string
 
-	// The arguments the route can take
-	// [][3]string // Map of argument to the description and default value
-	Arguments() [][3]string
+	// The arguments the route can take. These are validated and coerced by the router before Render
+	Arguments() []Argument
 
 	// Given a current state, sets up all state for the route
 	Setup(state *state.State) error
@@ -127,6 +204,45 @@ type Route interface {
 	Render(state *state.State, args map[string]string) error
 }
 
+// Routes implementing DataRoute can return their result as data, for commands that post-process
+// the result of another route (e.g. watch)
+type DataRoute interface {
+	RenderData(state *state.State, args map[string]string) (any, error)
+}
+
 type CompletableRoute interface {
 	Completion(state *state.State, line string, args map[string]string) ([]string, error)
 }
+
+// Runs fn with os.Stdout redirected, returning everything written to it
+func captureStdout(fn func() error) (string, error) {
+	r, w, err := os.Pipe()
+
+	if err != nil {
+		return "", err
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	var outChan = make(chan string)
+
+	go func() {
+		b, _ := io.ReadAll(r)
+		outChan <- string(b)
+	}()
+
+	fnErr := func() error {
+		defer func() {
+			os.Stdout = stdout
+			w.Close()
+		}()
+
+		return fn()
+	}()
+
+	out := <-outChan
+	r.Close()
+
+	return out, fnErr
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/loc"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type gotoTestRoute struct {
+	testRoute
+	destroyed int
+}
+
+func (r *gotoTestRoute) Command() string { return "test.goto" }
+
+func (r *gotoTestRoute) Destroy(state *state.State) error {
+	r.destroyed++
+	return nil
+}
+
+func TestGotoValidatesBeforeSwitching(t *testing.T) {
+	r := &gotoTestRoute{testRoute: testRoute{args: []Argument{{Name: "count", Type: ArgTypeInt, Required: true}}}}
+	AddRoute(r)
+
+	s := &state.State{CurrentLoc: &loc.LocMetadata{ID: "test.goto", Data: map[string]string{"count": "1"}}}
+
+	// An invalid argument leaves the current location and route alone
+	err := Goto("test.goto", s, map[string]string{"count": "abc"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Equal(t, map[string]string{"count": "1"}, s.CurrentLoc.Data)
+	assert.Equal(t, 0, r.destroyed)
+
+	err = Goto("test.goto", s, map[string]string{"count": "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": "2"}, s.CurrentLoc.Data)
+	assert.Equal(t, 1, r.destroyed)
+}
@@ -0,0 +1,194 @@
+package apiexec_bench
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+)
+
+// The percentiles reported by a benchmark
+var percentiles = []float64{50, 90, 99}
+
+// ErrorCount is the number of failed requests with a given status and error type
+type ErrorCount struct {
+	Status    int    `json:"status"`
+	ErrorType string `json:"error_type"`
+	Count     int    `json:"count"`
+
+	// An example error message
+	Example string `json:"example"`
+}
+
+// Result is the report of a benchmark
+type Result struct {
+	Route       string        `json:"route"`
+	Requests    int           `json:"requests"`
+	Concurrency int           `json:"concurrency"`
+	Raw         bool          `json:"raw"`
+	Duration    time.Duration `json:"duration"`
+
+	// Completed requests per second
+	Throughput float64 `json:"throughput"`
+
+	Succeeded int `json:"succeeded"`
+
+	// Latencies of all requests. When not in raw mode, these include time spent waiting on ratelimits
+	Min         time.Duration            `json:"min"`
+	Max         time.Duration            `json:"max"`
+	Percentiles map[string]time.Duration `json:"percentiles"`
+
+	// Ratelimited responses in total and how many requests hit at least one
+	Ratelimits          int          `json:"ratelimits"`
+	RatelimitedRequests int          `json:"ratelimited_requests"`
+	Errors              []ErrorCount `json:"errors"`
+}
+
+// Returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(p/100*float64(len(sorted))+0.999999) - 1
+
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+func summarize(route string, samples []sample, concurrency int, raw bool, elapsed time.Duration) *Result {
+	res := &Result{
+		Route:       route,
+		Concurrency: concurrency,
+		Raw:         raw,
+		Duration:    elapsed,
+		Percentiles: map[string]time.Duration{},
+	}
+
+	var durations []time.Duration
+	var counts = map[[2]string]*ErrorCount{}
+
+	for _, s := range samples {
+		// Requests that were never started because the benchmark was cancelled
+		if s.duration == 0 && s.err == nil && s.status == 0 {
+			continue
+		}
+
+		res.Requests++
+		durations = append(durations, s.duration)
+
+		res.Ratelimits += s.ratelimits
+		if s.ratelimits > 0 {
+			res.RatelimitedRequests++
+		}
+
+		if s.err == nil {
+			res.Succeeded++
+			continue
+		}
+
+		key := [2]string{strconv.Itoa(s.status), s.errorType}
+
+		if counts[key] == nil {
+			counts[key] = &ErrorCount{Status: s.status, ErrorType: s.errorType, Example: apiexec_exec.FirstLine(s.err.Error())}
+		}
+
+		counts[key].Count++
+	}
+
+	if res.Requests == 0 {
+		return res
+	}
+
+	slices.Sort(durations)
+
+	res.Min = durations[0]
+	res.Max = durations[len(durations)-1]
+
+	for _, p := range percentiles {
+		res.Percentiles["p"+strconv.FormatFloat(p, 'f', -1, 64)] = percentile(durations, p)
+	}
+
+	if elapsed > 0 {
+		res.Throughput = float64(res.Requests) / elapsed.Seconds()
+	}
+
+	for _, e := range counts {
+		res.Errors = append(res.Errors, *e)
+	}
+
+	sort.Slice(res.Errors, func(i, j int) bool {
+		if res.Errors[i].Count != res.Errors[j].Count {
+			return res.Errors[i].Count > res.Errors[j].Count
+		}
+
+		return res.Errors[i].Status < res.Errors[j].Status
+	})
+
+	return res
+}
+
+// Prints the report
+func (r *Result) Print() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Route:\t%s\n", r.Route)
+	fmt.Fprintf(w, "Requests:\t%d (concurrency %d)\n", r.Requests, r.Concurrency)
+	fmt.Fprintf(w, "Duration:\t%s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "Throughput:\t%.1f req/s\n", r.Throughput)
+	fmt.Fprintf(w, "Succeeded:\t%d/%d\n", r.Succeeded, r.Requests)
+
+	latency := fmt.Sprintf("min %s", round(r.Min))
+	for _, p := range percentiles {
+		name := "p" + strconv.FormatFloat(p, 'f', -1, 64)
+		latency += fmt.Sprintf("  %s %s", name, round(r.Percentiles[name]))
+	}
+	latency += fmt.Sprintf("  max %s", round(r.Max))
+
+	fmt.Fprintf(w, "Latency:\t%s\n", latency)
+
+	if r.Raw {
+		fmt.Fprintf(w, "Ratelimits:\t%d request(s) rejected\n", r.RatelimitedRequests)
+	} else {
+		fmt.Fprintf(w, "Ratelimits:\t%d hit(s) across %d request(s), waited for Retry-After (included in latency)\n", r.Ratelimits, r.RatelimitedRequests)
+	}
+
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	if len(r.Errors) == 0 {
+		return
+	}
+
+	fmt.Println("\nErrors:")
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STATUS\tERROR TYPE\tCOUNT\tEXAMPLE")
+
+	for _, e := range r.Errors {
+		status := "-"
+
+		if e.Status != 0 {
+			status = strconv.Itoa(e.Status)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", status, e.ErrorType, e.Count, e.Example)
+	}
+
+	//nolint:errcheck
+	w.Flush()
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+
+	return d.Round(100 * time.Microsecond)
+}
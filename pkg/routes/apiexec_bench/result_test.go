@@ -0,0 +1,42 @@
+package apiexec_bench
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(sorted, 100))
+	assert.Equal(t, 7*time.Millisecond, percentile(sorted[6:7], 1))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
+
+func TestSummarize(t *testing.T) {
+	ratelimited := errors.New("API error: ratelimited")
+
+	res := summarize("getModules", []sample{
+		{duration: 3 * time.Millisecond, status: 200},
+		{duration: time.Millisecond, status: 200, ratelimits: 2},
+		{duration: 2 * time.Millisecond, status: 429, errorType: "ratelimit", err: ratelimited, ratelimits: 1},
+		{}, // never started
+	}, 2, true, time.Second)
+
+	assert.Equal(t, 3, res.Requests)
+	assert.Equal(t, 2, res.Succeeded)
+	assert.Equal(t, 3, res.Ratelimits)
+	assert.Equal(t, 2, res.RatelimitedRequests)
+	assert.Equal(t, time.Millisecond, res.Min)
+	assert.Equal(t, 2*time.Millisecond, res.Percentiles["p50"])
+	assert.Equal(t, 3.0, res.Throughput)
+	assert.Equal(t, []ErrorCount{{Status: 429, ErrorType: "ratelimit", Count: 1, Example: "API error: ratelimited"}}, res.Errors)
+}
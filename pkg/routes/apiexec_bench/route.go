@@ -0,0 +1,217 @@
+package apiexec_bench
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+// The declared arguments that are not request fields
+var benchArgs = []string{"n", "c", "raw", "quiet", "allow_writes"}
+
+// The result of a single Exec call
+type sample struct {
+	duration time.Duration
+
+	// The status of the final response, 0 if there was none
+	status    int
+	errorType string
+	err       error
+
+	// How many ratelimited responses (429 or a Retry-After header) were received
+	ratelimits int
+}
+
+type ApiExecBenchRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *ApiExecBenchRoute) Command() string {
+	return "apiexec.bench"
+}
+
+func (r *ApiExecBenchRoute) Description() string {
+	return "Executes a route repeatedly, reporting throughput, latency percentiles, errors and ratelimits"
+}
+
+func (r *ApiExecBenchRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The ID of the route to benchmark", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+		{Name: "n", Description: "The total number of requests", Type: router.ArgTypeInt, Default: "100"},
+		{Name: "c", Description: "How many requests to run at once", Type: router.ArgTypeInt, Default: "10"},
+		{Name: "raw", Description: "Do not wait for Retry-After on ratelimits, measuring raw rejections instead", Type: router.ArgTypeBool, Default: "false"},
+		{Name: "quiet", Description: "Hide per-request info logs while benchmarking", Type: router.ArgTypeBool, Default: "true"},
+		{Name: "allow_writes", Description: "Allow benchmarking routes that are not GET requests. Every request may modify data on the instance", Type: router.ArgTypeBool, Default: "false"},
+	}
+}
+
+// Any argument that is not declared is a request field in KEY::TYPE=VALUE form, sent with every request
+func (r *ApiExecBenchRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *ApiExecBenchRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *ApiExecBenchRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+func (r *ApiExecBenchRoute) Render(state *state.State, args map[string]string) error {
+	res, err := r.bench(state, args)
+
+	if err != nil {
+		return err
+	}
+
+	res.Print()
+
+	return nil
+}
+
+func (r *ApiExecBenchRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return r.bench(state, args)
+}
+
+func (r *ApiExecBenchRoute) bench(state *state.State, args map[string]string) (*Result, error) {
+	route := api.GetTestableRoute(args["route"])
+
+	if route == nil {
+		return nil, fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, args["route"])
+	}
+
+	if err := apiexec_exec.CheckRepeatedWrites(state, route, args["allow_writes"] == "true", "benchmark"); err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(args["n"])
+
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("%w: n must be at least 1", router.ErrInvalidArgument)
+	}
+
+	c, err := strconv.Atoi(args["c"])
+
+	if err != nil || c < 1 {
+		return nil, fmt.Errorf("%w: c must be at least 1", router.ErrInvalidArgument)
+	}
+
+	reqArgs := map[string]string{}
+	for k, v := range args {
+		if !slices.Contains(benchArgs, k) {
+			reqArgs[k] = v
+		}
+	}
+
+	fields, err := apiexec_exec.ParseRequestArgs(state, reqArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	populated, err := route.PopulateWithArgs(fields)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate route with args: %w", err)
+	}
+
+	// Settings operations are validated against the modules fetched for the first request only, so the
+	// validation does not skew the timings of the others
+	ctx := guilds.WithModulesCache(r.ctx)
+	raw := args["raw"] == "true"
+
+	if raw {
+		ctx = fetch.WithNoWait(ctx)
+	}
+
+	// Logging every request would drown out the report and slow down the benchmark
+	if args["quiet"] == "true" {
+		prevLevel := slog.SetLogLoggerLevel(slog.LevelWarn)
+		defer slog.SetLogLoggerLevel(prevLevel)
+	}
+
+	fmt.Printf("Benchmarking %s with %d request(s), %d at a time...\n", route.ID(), n, c)
+
+	samples := make([]sample, n)
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for range min(c, n) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				i := int(next.Add(1)) - 1
+
+				if i >= n || ctx.Err() != nil {
+					return
+				}
+
+				samples[i] = run(ctx, state, populated)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return summarize(route.ID(), samples, c, raw, time.Since(start)), nil
+}
+
+// Executes the route once, recording all responses to count ratelimits
+func run(ctx context.Context, state *state.State, route api.TestableRoute) sample {
+	rec := &fetch.Recorder{}
+
+	start := time.Now()
+	_, err := route.Exec(fetch.WithRecorder(ctx, rec), state)
+
+	s := sample{duration: time.Since(start), err: err}
+
+	exchanges := rec.Exchanges()
+
+	for i, ex := range exchanges {
+		if ex.Headers.Get("Retry-After") != "" || ex.Status == 429 {
+			// The final response is only a ratelimit hit if it was returned without retrying
+			if i < len(exchanges)-1 || err != nil {
+				s.ratelimits++
+			}
+		}
+	}
+
+	if len(exchanges) > 0 {
+		last := exchanges[len(exchanges)-1]
+		s.status = last.Status
+		s.errorType = last.ErrorType
+	}
+
+	if err != nil && s.errorType == "" {
+		s.errorType = fetch.ErrorType(err)
+	}
+
+	return s
+}
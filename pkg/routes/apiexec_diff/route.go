@@ -0,0 +1,335 @@
+package apiexec_diff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/constants"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/jsonutil"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+)
+
+var ErrResponsesDiffer = errors.New("responses differ")
+
+// Fields that change on every request or between instances. IDs of entries created separately on each instance
+// (such as job or command configuration IDs) differ as well, but share their name with IDs that are stable
+// between instances (such as module IDs) so they are not ignored unless **.id is added
+const defaultIgnore = "**.created_at,**.updated_at,**.last_updated_at,**.expiry,**.session_id,**.last_message_id"
+
+// How long values are in the diff unless full is set
+const valueLength = 100
+
+// The declared arguments that are not request fields
+var diffArgs = []string{"left", "right", "left.session", "right.session", "left.token", "right.token", "ignore", "full"}
+
+// The response of one side of the diff
+type Side struct {
+	Instance string        `json:"instance"`
+	Status   int           `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+
+	// The normalized response with ignored paths masked
+	Body any `json:"body"`
+}
+
+type DiffResult struct {
+	Left    Side              `json:"left"`
+	Right   Side              `json:"right"`
+	Changes []jsonutil.Change `json:"changes"`
+}
+
+type ApiExecDiffRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *ApiExecDiffRoute) Command() string {
+	return "apiexec.diff"
+}
+
+func (r *ApiExecDiffRoute) Description() string {
+	return "Executes a route against two instances and shows a structural diff of the responses"
+}
+
+func (r *ApiExecDiffRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The ID of the route to execute", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+		{Name: "left", Description: "The instance URL of the left side, current for the current instance", Type: router.ArgTypeString, Required: true, Completion: completeInstances},
+		{Name: "right", Description: "The instance URL of the right side, current for the current instance", Type: router.ArgTypeString, Required: true, Completion: completeInstances},
+		{Name: "left.session", Description: "The number of the saved session to use for the left side", Type: router.ArgTypeInt, DefaultHelp: "[current session on the current instance]"},
+		{Name: "right.session", Description: "The number of the saved session to use for the right side", Type: router.ArgTypeInt, DefaultHelp: "[current session on the current instance]"},
+		{Name: "left.token", Description: "A token to use for the left side instead of a saved session, e.g. ${env:PROD_TOKEN}", Type: router.ArgTypeString},
+		{Name: "right.token", Description: "A token to use for the right side instead of a saved session, e.g. ${env:STAGING_TOKEN}", Type: router.ArgTypeString},
+		{Name: "ignore", Description: "Comma-separated paths to ignore, * matches one segment and ** any number of segments. Replaces the defaults, add **.id to also ignore entry IDs that differ between instances", Type: router.ArgTypeString, Default: defaultIgnore},
+		{Name: "full", Description: "Show full values instead of truncating them", Type: router.ArgTypeBool, Default: "false"},
+	}
+}
+
+// Any argument that is not declared is a request field in KEY::TYPE=VALUE form, sent to both instances
+func (r *ApiExecDiffRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *ApiExecDiffRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *ApiExecDiffRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+func (r *ApiExecDiffRoute) Render(state *state.State, args map[string]string) error {
+	res, err := r.diff(state, args)
+
+	if err != nil {
+		return err
+	}
+
+	full := args["full"] == "true"
+
+	printSide("left ", res.Left)
+	printSide("right", res.Right)
+	fmt.Println()
+
+	if len(res.Changes) == 0 {
+		fmt.Println(ansi.Color(ansi.Green, "No differences"))
+		return nil
+	}
+
+	for _, c := range res.Changes {
+		switch c.Kind {
+		case jsonutil.ChangeAdded:
+			fmt.Println(ansi.Color(ansi.Green, "+ "+c.Path+": "+format(c.Right, full)))
+		case jsonutil.ChangeRemoved:
+			fmt.Println(ansi.Color(ansi.Red, "- "+c.Path+": "+format(c.Left, full)))
+		case jsonutil.ChangeModified:
+			fmt.Println(ansi.Color(ansi.Yellow, "~ "+c.Path+": "+format(c.Left, full)+" -> "+format(c.Right, full)))
+		}
+	}
+
+	fmt.Printf("\n%d difference(s)\n", len(res.Changes))
+
+	return fmt.Errorf("%w: %d difference(s)", ErrResponsesDiffer, len(res.Changes))
+}
+
+func (r *ApiExecDiffRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return r.diff(state, args)
+}
+
+func (r *ApiExecDiffRoute) diff(s *state.State, args map[string]string) (*DiffResult, error) {
+	route := api.GetTestableRoute(args["route"])
+
+	if route == nil {
+		return nil, fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, args["route"])
+	}
+
+	reqArgs := map[string]string{}
+	for k, v := range args {
+		if !slices.Contains(diffArgs, k) {
+			reqArgs[k] = v
+		}
+	}
+
+	fields, err := apiexec_exec.ParseRequestArgs(s, reqArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sideStates [2]*state.State
+	for i, side := range []string{"left", "right"} {
+		sideStates[i], err = sideState(s, route, side, args)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sides [2]Side
+	var wg sync.WaitGroup
+	for i := range sides {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			sides[i] = r.exec(sideStates[i], route, fields)
+		}()
+	}
+
+	wg.Wait()
+
+	ignore := strings.Split(args["ignore"], ",")
+
+	for i := range sides {
+		sides[i].Body = jsonutil.Mask(sides[i].Body, ignore)
+	}
+
+	res := &DiffResult{Left: sides[0], Right: sides[1]}
+
+	if res.Left.Status != res.Right.Status {
+		res.Changes = append(res.Changes, jsonutil.Change{Kind: jsonutil.ChangeModified, Path: "(status)", Left: res.Left.Status, Right: res.Right.Status})
+	}
+
+	res.Changes = append(res.Changes, jsonutil.Diff(res.Left.Body, res.Right.Body)...)
+
+	return res, nil
+}
+
+// Executes the route on one side, recording the raw response so fields unknown to the Go types are diffed as well
+func (r *ApiExecDiffRoute) exec(s *state.State, route api.TestableRoute, fields map[string]any) Side {
+	side := Side{Instance: s.StateFetchOptions.InstanceAPIUrl}
+
+	populated, err := route.PopulateWithArgs(fields)
+
+	if err != nil {
+		side.Error = err.Error()
+		return side
+	}
+
+	rec := &fetch.Recorder{}
+
+	_, err = populated.Exec(fetch.WithRecorder(r.ctx, rec), s)
+
+	if err != nil {
+		side.Error = err.Error()
+	}
+
+	ex := rec.Last()
+
+	if ex == nil {
+		return side
+	}
+
+	side.Status = ex.Status
+	side.Duration = ex.Duration
+
+	if len(ex.Body) == 0 {
+		return side
+	}
+
+	var body any
+	if err := json.Unmarshal(ex.Body, &body); err != nil {
+		// Non-JSON responses (such as error pages) are compared as strings
+		body = string(ex.Body)
+	}
+
+	if side.Body, err = jsonutil.Normalize(body); err != nil {
+		side.Body = string(ex.Body)
+	}
+
+	return side
+}
+
+// Creates the state of one side of the diff, with its own instance and session. The current session is only
+// used for the current instance, see apiexec_exec.TargetState
+func sideState(base *state.State, route api.TestableRoute, side string, args map[string]string) (*state.State, error) {
+	instance := args[side]
+
+	if instance == "current" {
+		instance = base.StateFetchOptions.InstanceAPIUrl
+	}
+
+	if instance == "" {
+		return nil, fmt.Errorf("%w: %s: no instance URL given", router.ErrInvalidArgument, side)
+	}
+
+	sess, err := sideSession(base, side, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return apiexec_exec.TargetState(base, route, instance, sess, side+".token")
+}
+
+// Returns the session explicitly given for one side, or nil if there is none
+func sideSession(base *state.State, side string, args map[string]string) (*types.CreateUserSessionResponse, error) {
+	if token := args[side+".token"]; token != "" {
+		sess, err := apiexec_exec.TokenSession(base, token)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s.token: %w", side, err)
+		}
+
+		return sess, nil
+	}
+
+	if n := args[side+".session"]; n != "" {
+		idx, err := strconv.Atoi(n)
+
+		base.Session.RemoveExpiredSessions()
+
+		if err != nil || idx < 1 || idx > len(base.Session.UserSessions) {
+			return nil, fmt.Errorf("%w: %s.session must be between 1 and %d", router.ErrInvalidArgument, side, len(base.Session.UserSessions))
+		}
+
+		return base.Session.UserSessions[idx-1], nil
+	}
+
+	return nil, nil
+}
+
+func completeInstances(s *state.State, partial string) ([]string, error) {
+	var c []string
+
+	for _, instance := range []string{"current", s.StateFetchOptions.InstanceAPIUrl, constants.DefaultInstanceUrl} {
+		if instance != "" && strings.HasPrefix(instance, partial) {
+			c = append(c, instance)
+		}
+	}
+
+	return c, nil
+}
+
+func printSide(name string, side Side) {
+	status := strconv.Itoa(side.Status)
+
+	if side.Status == 0 {
+		status = "no response"
+	}
+
+	line := fmt.Sprintf("%s %s: %s (%s)", name, side.Instance, status, side.Duration.Round(time.Millisecond))
+
+	if side.Error != "" {
+		line += ": " + apiexec_exec.FirstLine(side.Error)
+	}
+
+	fmt.Println(line)
+}
+
+// Returns the compact JSON form of a value, truncated unless full is set
+func format(v any, full bool) string {
+	b, err := json.Marshal(v)
+
+	s := string(b)
+	if err != nil {
+		s = fmt.Sprint(v)
+	}
+
+	if full {
+		return s
+	}
+
+	return apiexec_exec.Truncate(s, valueLength)
+}
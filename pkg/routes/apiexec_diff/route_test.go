@@ -0,0 +1,78 @@
+package apiexec_diff
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRoute struct {
+	meta api.RouteMeta
+}
+
+func (r *testRoute) ID() string { return "testRoute" }
+
+func (r *testRoute) PopulateWithArgs(args map[string]any) (api.TestableRoute, error) { return r, nil }
+
+func (r *testRoute) ReqType() any { return &struct{}{} }
+
+func (r *testRoute) RespType() any { return nil }
+
+func (r *testRoute) Exec(ctx context.Context, state *state.State) (any, error) { return nil, nil }
+
+func (r *testRoute) Meta() api.RouteMeta { return r.meta }
+
+func TestSideState(t *testing.T) {
+	t.Setenv("EB_TEST_TOKEN", "staging-token")
+
+	s := &state.State{}
+	s.StateFetchOptions.InstanceAPIUrl = "http://localhost:3010"
+	s.Session.UserSessions = []*types.CreateUserSessionResponse{
+		{UserID: "1", Token: "current-token", Expiry: time.Now().Add(time.Hour)},
+		{UserID: "2", Token: "saved-token", Expiry: time.Now().Add(time.Hour)},
+	}
+
+	authed := &testRoute{meta: api.RouteMeta{Method: "GET", Path: "/things", Auth: true}}
+	public := &testRoute{meta: api.RouteMeta{Method: "GET", Path: "/things"}}
+
+	// The current instance uses the current session
+	target, err := sideState(s, authed, "left", map[string]string{"left": "current"})
+	assert.NoError(t, err)
+	assert.Same(t, s, target)
+
+	target, err = sideState(s, authed, "left", map[string]string{"left": "http://localhost:3010/"})
+	assert.NoError(t, err)
+	assert.Same(t, s, target)
+
+	// Other instances are never sent the current session
+	_, err = sideState(s, authed, "right", map[string]string{"right": "https://staging.example.com"})
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+	assert.ErrorContains(t, err, "set right.token")
+
+	target, err = sideState(s, public, "right", map[string]string{"right": "https://staging.example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", target.StateFetchOptions.InstanceAPIUrl)
+	assert.Empty(t, target.Session.UserSessions)
+
+	// Explicit tokens and saved sessions are used for any instance
+	target, err = sideState(s, authed, "right", map[string]string{"right": "https://staging.example.com/", "right.token": "${env:EB_TEST_TOKEN}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", target.StateFetchOptions.InstanceAPIUrl)
+	assert.Equal(t, "staging-token", target.Session.PeekCurrentSession().Token)
+
+	target, err = sideState(s, authed, "right", map[string]string{"right": "https://staging.example.com", "right.session": "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "saved-token", target.Session.PeekCurrentSession().Token)
+
+	_, err = sideState(s, authed, "right", map[string]string{"right": "https://staging.example.com", "right.session": "3"})
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+
+	_, err = sideState(s, authed, "left", map[string]string{})
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+}
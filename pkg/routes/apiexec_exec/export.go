@@ -0,0 +1,268 @@
+package apiexec_exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+var ErrNoRequest = errors.New("route does not make a request")
+
+// The environment variable exported requests read the token from when it is redacted
+const tokenEnv = "ANTIRAID_TOKEN"
+
+// The formats requests can be exported as
+var exportFormats = []string{"curl", "httpie", "go"}
+
+// Prints a captured request in an export format
+func printExport(s *state.State, route api.TestableRoute, fields map[string]any, req *fetch.Request, format string, secrets bool) error {
+	switch format {
+	case "curl":
+		fmt.Println(exportCurl(req, secrets))
+	case "httpie":
+		fmt.Println(exportHttpie(req, secrets))
+	case "go":
+		src, err := exportGo(s, route, fields, req, secrets)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(src)
+	default:
+		return fmt.Errorf("%w: unsupported export format %s", router.ErrInvalidArgument, format)
+	}
+
+	return nil
+}
+
+// Returns the Authorization header to export, reading the token from tokenEnv unless secrets is set
+func exportAuthorization(req *fetch.Request, secrets bool) (string, bool) {
+	auth, ok := req.Headers["Authorization"]
+
+	if !ok || secrets {
+		return auth, ok
+	}
+
+	return "User $" + tokenEnv, true
+}
+
+// Quotes a string for POSIX shells
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r))
+	}) == -1 {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Like shellQuote, but keeps $VARIABLES expandable
+func shellQuoteExpand(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`").Replace(s) + `"`
+}
+
+// Returns the headers of a request other than Authorization, sorted by name
+func exportHeaders(req *fetch.Request) []string {
+	var names []string
+
+	for k := range req.Headers {
+		if k != "Authorization" {
+			names = append(names, k)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Renders a request as a curl command
+func exportCurl(req *fetch.Request, secrets bool) string {
+	lines := []string{"curl -X " + req.Method + " " + shellQuote(req.URL)}
+
+	for _, k := range exportHeaders(req) {
+		lines = append(lines, "-H "+shellQuote(k+": "+req.Headers[k]))
+	}
+
+	if auth, ok := exportAuthorization(req, secrets); ok {
+		if secrets {
+			lines = append(lines, "-H "+shellQuote("Authorization: "+auth))
+		} else {
+			lines = append(lines, "-H "+shellQuoteExpand("Authorization: "+auth))
+		}
+	}
+
+	if req.Body != nil {
+		lines = append(lines, "--data-raw "+shellQuote(string(req.Body)))
+	}
+
+	return strings.Join(lines, " \\\n  ")
+}
+
+// Renders a request as an HTTPie command
+func exportHttpie(req *fetch.Request, secrets bool) string {
+	lines := []string{"http " + req.Method + " " + shellQuote(req.URL)}
+
+	for _, k := range exportHeaders(req) {
+		lines = append(lines, shellQuote(k+":"+req.Headers[k]))
+	}
+
+	if auth, ok := exportAuthorization(req, secrets); ok {
+		if secrets {
+			lines = append(lines, shellQuote("Authorization:"+auth))
+		} else {
+			lines = append(lines, shellQuoteExpand("Authorization:"+auth))
+		}
+	}
+
+	if req.Body != nil {
+		lines = append(lines, "--raw "+shellQuote(string(req.Body)))
+	}
+
+	return strings.Join(lines, " \\\n  ")
+}
+
+// Renders a Go program calling the API function of a route with the given request fields
+func exportGo(s *state.State, route api.TestableRoute, fields map[string]any, req *fetch.Request, secrets bool) (string, error) {
+	fn := api.RouteFunc(route)
+
+	if fn == nil {
+		return "", fmt.Errorf("route %s does not wrap an API function", route.ID())
+	}
+
+	fnType := reflect.TypeOf(fn)
+	fnName := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+
+	// e.g. github.com/anti-raid/evil-befall/pkg/api/guilds.GetStaffTeam
+	lastSlash := strings.LastIndex(fnName, "/")
+	dot := lastSlash + strings.Index(fnName[lastSlash:], ".")
+	fnPkg, fnIdent := fnName[:dot], fnName[dot+1:]
+	fnQualified := fnPkg[lastSlash+1:] + "." + fnIdent
+
+	imports := []string{"context", "encoding/json", "fmt", fnPkg, "github.com/anti-raid/evil-befall/pkg/state"}
+
+	var b strings.Builder
+
+	b.WriteString("func main() {\n")
+
+	if auth, ok := req.Headers["Authorization"]; ok {
+		imports = append(imports, "time", "github.com/anti-raid/evil-befall/types")
+
+		token := fmt.Sprintf("os.Getenv(%q)", tokenEnv)
+
+		if secrets {
+			token = fmt.Sprintf("%q", strings.TrimPrefix(auth, "User "))
+		} else {
+			imports = append(imports, "os")
+		}
+
+		fmt.Fprintf(&b, "s := (&state.State{}).WithInstance(%q, &types.CreateUserSessionResponse{\n", s.StateFetchOptions.InstanceAPIUrl)
+		fmt.Fprintf(&b, "Token: %s,\nExpiry: time.Now().Add(time.Hour),\n})\n\n", token)
+	} else {
+		fmt.Fprintf(&b, "s := (&state.State{}).WithInstance(%q)\n\n", s.StateFetchOptions.InstanceAPIUrl)
+	}
+
+	args := "context.Background(), s"
+
+	// Functions taking request data have it as their third parameter
+	if fnType.NumIn() == 3 {
+		dataType := fnType.In(2)
+
+		base := dataType
+		for base.Kind() == reflect.Ptr {
+			base = base.Elem()
+		}
+
+		if base.PkgPath() != "" && base.PkgPath() != fnPkg && !slices.Contains(imports, base.PkgPath()) {
+			imports = append(imports, base.PkgPath())
+		}
+
+		body, err := json.MarshalIndent(fields, "", "  ")
+
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&b, "var data %s\n\n", dataType.String())
+		fmt.Fprintf(&b, "if err := json.Unmarshal([]byte(%s), &data); err != nil {\npanic(err)\n}\n\n", goRawString(string(body)))
+
+		args += ", data"
+	}
+
+	// Functions returning a response have (*RespType, error), others only error
+	if fnType.NumOut() == 2 {
+		fmt.Fprintf(&b, "resp, err := %s(%s)\n\n", fnQualified, args)
+		b.WriteString("if err != nil {\npanic(err)\n}\n\n")
+		b.WriteString("out, err := json.MarshalIndent(resp, \"\", \"  \")\n\n")
+		b.WriteString("if err != nil {\npanic(err)\n}\n\n")
+		b.WriteString("fmt.Println(string(out))\n")
+	} else {
+		fmt.Fprintf(&b, "if err := %s(%s); err != nil {\npanic(err)\n}\n\n", fnQualified, args)
+		b.WriteString("fmt.Println(\"OK\")\n")
+	}
+
+	b.WriteString("}\n")
+
+	slices.SortFunc(imports, func(a, b string) int {
+		if isStdlib(a) != isStdlib(b) {
+			if isStdlib(a) {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	var src bytes.Buffer
+
+	src.WriteString("package main\n\nimport (\n")
+
+	// Standard library imports first, then a blank line and the others
+	for i, imp := range imports {
+		if i > 0 && isStdlib(imports[i-1]) && !isStdlib(imp) {
+			src.WriteString("\n")
+		}
+
+		fmt.Fprintf(&src, "%q\n", imp)
+	}
+
+	src.WriteString(")\n\n")
+	src.WriteString(b.String())
+
+	formatted, err := format.Source(src.Bytes())
+
+	if err != nil {
+		return "", fmt.Errorf("failed to format Go export: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// Returns a Go raw string literal of s, falling back to an interpreted literal if s contains backquotes
+func goRawString(s string) string {
+	if strings.Contains(s, "`") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return "`" + s + "`"
+}
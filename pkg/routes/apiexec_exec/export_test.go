@@ -0,0 +1,25 @@
+package apiexec_exec
+
+import (
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCurl(t *testing.T) {
+	req := &fetch.Request{
+		Method:  "POST",
+		URL:     "http://localhost/guilds/1/settings?a=b&c=d",
+		Headers: map[string]string{"Content-Type": "application/json", "Authorization": "User secret"},
+		Body:    []byte(`{"name":"it's"}`),
+	}
+
+	assert.Equal(t, `curl -X POST 'http://localhost/guilds/1/settings?a=b&c=d' \
+  -H 'Content-Type: application/json' \
+  -H "Authorization: User $ANTIRAID_TOKEN" \
+  --data-raw '{"name":"it'\''s"}'`, exportCurl(req, false))
+
+	assert.Contains(t, exportCurl(req, true), `-H 'Authorization: User secret'`)
+	assert.NotContains(t, exportHttpie(req, false), "secret")
+}
@@ -0,0 +1,18 @@
+package apiexec_exec
+
+import "strings"
+
+// Returns the first line of s, ignoring leading and trailing whitespace
+func FirstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// Shortens s to n characters, ending it with … if it was cut
+func Truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n-1]) + "…"
+}
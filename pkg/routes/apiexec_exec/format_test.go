@@ -0,0 +1,21 @@
+package apiexec_exec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstLine(t *testing.T) {
+	assert.Equal(t, "bad request", FirstLine("\n  bad request\nmore details\n"))
+	assert.Equal(t, "single", FirstLine("single"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 5))
+
+	long := Truncate(strings.Repeat("é", 100), 60)
+	assert.Len(t, []rune(long), 60)
+	assert.Equal(t, strings.Repeat("é", 59)+"…", long)
+}
@@ -0,0 +1,246 @@
+package apiexec_exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownPlaceholder = errors.New("unknown placeholder")
+
+// Whether commands are read from stdin (--command -), in which case stdin cannot be used for values with @-
+var CommandsFromStdin bool
+
+// Matches $${...} (an escaped placeholder) and ${...}
+var placeholderRegex = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// Input resolves request values from files, stdin and placeholders
+type Input struct {
+	State *state.State
+	Stdin io.Reader
+
+	// Whether stdin has already been read
+	stdinUsed bool
+}
+
+func NewInput(state *state.State) *Input {
+	in := &Input{State: state, Stdin: os.Stdin}
+
+	if CommandsFromStdin {
+		in.Stdin = nil
+	}
+
+	return in
+}
+
+// Expands placeholders in s
+//
+// Supported placeholders are ${env:VAR}, ${state.selected_guild}, ${state.user_id} and ${state.instance_url}.
+// $${...} is left as a literal ${...}
+func (in *Input) Expand(s string) (string, error) {
+	var expandErr error
+
+	expanded := placeholderRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+
+		name := m[2 : len(m)-1]
+
+		v, err := in.placeholder(name)
+
+		if err != nil && expandErr == nil {
+			expandErr = err
+		}
+
+		return v
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	return expanded, nil
+}
+
+func (in *Input) placeholder(name string) (string, error) {
+	if env, ok := strings.CutPrefix(name, "env:"); ok {
+		v, ok := os.LookupEnv(env)
+
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", env)
+		}
+
+		return v, nil
+	}
+
+	switch name {
+	case "state.selected_guild":
+		if in.State.SelectedOptions.GuildID == "" {
+			return "", errors.New("${state.selected_guild} used but no guild is selected, use choose_guild to select one")
+		}
+
+		return in.State.SelectedOptions.GuildID, nil
+	case "state.user_id":
+		sess := in.State.Session.PeekCurrentSession()
+
+		if sess == nil {
+			return "", errors.New("${state.user_id} used but there is no session, use login to create one")
+		}
+
+		return sess.UserID, nil
+	case "state.instance_url":
+		return in.State.StateFetchOptions.InstanceAPIUrl, nil
+	}
+
+	return "", fmt.Errorf("%w: ${%s}", ErrUnknownPlaceholder, name)
+}
+
+// Reads the contents of @file or @- (stdin). Only one value may be read from stdin
+func (in *Input) readRef(ref string) ([]byte, error) {
+	if ref == "-" {
+		if in.Stdin == nil {
+			return nil, errors.New("stdin (@-) cannot be used when commands are read from stdin")
+		}
+
+		if in.stdinUsed {
+			return nil, errors.New("stdin (@-) can only be used once")
+		}
+
+		in.stdinUsed = true
+
+		return io.ReadAll(in.Stdin)
+	}
+
+	return os.ReadFile(ref)
+}
+
+// Resolves a single argument value, reading @file and @- references and expanding placeholders
+//
+// References are only read from the argument as given, so a placeholder expanding to a value starting with @ is
+// never read as a file. File contents have a single trailing newline removed and their placeholders expanded.
+// Use @@ for a value starting with a literal @
+func (in *Input) Value(v string) (string, error) {
+	if strings.HasPrefix(v, "@@") {
+		return in.Expand(v[1:])
+	}
+
+	ref, ok := strings.CutPrefix(v, "@")
+
+	if !ok {
+		return in.Expand(v)
+	}
+
+	b, err := in.readRef(ref)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", v, err)
+	}
+
+	content := strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+
+	return in.Expand(content)
+}
+
+// Loads a full request from @file or @- in JSON or YAML form. Placeholders in string values are expanded
+//
+// The file must contain an object using the same keys as arguments, e.g. {"path:guildId": "...", "body": {...}}
+func (in *Input) Body(ref string) (map[string]any, error) {
+	ref, ok := strings.CutPrefix(ref, "@")
+
+	if !ok {
+		return nil, fmt.Errorf("__body must be @file or @-, got %s", ref)
+	}
+
+	ref, err := in.Expand(ref)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := in.readRef(ref)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body %s: %w", ref, err)
+	}
+
+	var body map[string]any
+
+	switch strings.ToLower(filepath.Ext(ref)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &body)
+	case ".json":
+		err = decodeJson(b, &body)
+	default:
+		// YAML is a superset of JSON but JSON is tried first to keep number handling identical
+		if json.Valid(bytes.TrimSpace(b)) {
+			err = decodeJson(b, &body)
+		} else {
+			err = yaml.Unmarshal(b, &body)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request body %s: %w", ref, err)
+	}
+
+	if body == nil {
+		body = map[string]any{}
+	}
+
+	if _, err := in.expandAll(body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// Decodes JSON keeping numbers as json.Number so large integers such as snowflakes are sent exactly as written
+func decodeJson(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// Expands placeholders in all strings of a decoded JSON/YAML value
+func (in *Input) expandAll(v any) (any, error) {
+	switch t := v.(type) {
+	case string:
+		return in.Expand(t)
+	case map[string]any:
+		for k, val := range t {
+			expanded, err := in.expandAll(val)
+
+			if err != nil {
+				return nil, err
+			}
+
+			t[k] = expanded
+		}
+
+		return t, nil
+	case []any:
+		for i, val := range t {
+			expanded, err := in.expandAll(val)
+
+			if err != nil {
+				return nil, err
+			}
+
+			t[i] = expanded
+		}
+
+		return t, nil
+	}
+
+	return v, nil
+}
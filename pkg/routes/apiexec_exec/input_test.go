@@ -0,0 +1,78 @@
+package apiexec_exec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInput(t *testing.T) {
+	t.Setenv("EB_TEST_TOKEN", "secret")
+
+	s := &state.State{}
+	s.SelectedOptions.GuildID = "123"
+
+	in := &Input{State: s, Stdin: strings.NewReader("from stdin\n")}
+
+	v, err := in.Value("guild-${state.selected_guild}-${env:EB_TEST_TOKEN}-$${env:EB_TEST_TOKEN}")
+	assert.NoError(t, err)
+	assert.Equal(t, "guild-123-secret-${env:EB_TEST_TOKEN}", v)
+
+	_, err = in.Value("${state.nope}")
+	assert.ErrorIs(t, err, ErrUnknownPlaceholder)
+
+	v, err = in.Value("@-")
+	assert.NoError(t, err)
+	assert.Equal(t, "from stdin", v)
+
+	_, err = in.Value("@-")
+	assert.Error(t, err)
+
+	v, err = in.Value("@@me")
+	assert.NoError(t, err)
+	assert.Equal(t, "@me", v)
+
+	dir := t.TempDir()
+
+	// References are read before placeholders are expanded, so values of placeholders are never read as files
+	t.Setenv("EB_TEST_REF", "@"+filepath.Join(dir, "secret"))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("file contents"), 0644))
+
+	v, err = in.Value("${env:EB_TEST_REF}")
+	assert.NoError(t, err)
+	assert.Equal(t, "@"+filepath.Join(dir, "secret"), v)
+
+	// File contents are expanded, but a placeholder in the path is not
+	value := filepath.Join(dir, "value.txt")
+	assert.NoError(t, os.WriteFile(value, []byte("guild ${state.selected_guild}\n"), 0644))
+
+	v, err = in.Value("@" + value)
+	assert.NoError(t, err)
+	assert.Equal(t, "guild 123", v)
+
+	_, err = in.Value("@${env:EB_TEST_REF}")
+	assert.ErrorContains(t, err, "failed to read @${env:EB_TEST_REF}")
+
+	file := filepath.Join(dir, "req.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("path:guildId: ${state.selected_guild}\nbody:\n  fields: [\"${env:EB_TEST_TOKEN}\"]\n"), 0644))
+
+	body, err := in.Body("@" + file)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"path:guildId": "123",
+		"body":         map[string]any{"fields": []any{"secret"}},
+	}, body)
+}
+
+func TestInputCommandsFromStdin(t *testing.T) {
+	CommandsFromStdin = true
+	defer func() { CommandsFromStdin = false }()
+
+	// Stdin holds the commands, so values cannot be read from it
+	_, err := NewInput(&state.State{}).Value("@-")
+	assert.ErrorContains(t, err, "cannot be used when commands are read from stdin")
+}
@@ -12,7 +12,13 @@ import (
 	"strings"
 
 	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/contract"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/jsontree"
+	"github.com/anti-raid/evil-befall/pkg/router"
 	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/pkg/tui/jsonview"
 	"github.com/anti-raid/shellcli/shell"
 	"github.com/anti-raid/spintrack/structstring"
 	"github.com/anti-raid/spintrack/strutils"
@@ -44,17 +50,29 @@ func (r *ApiExecExecRoute) Description() string {
 	return "Execute/Make a request to an API endpoint that can be tested"
 }
 
-func (r *ApiExecExecRoute) Arguments() [][3]string {
-	return [][3]string{
-		{"route", "Show detailed information about a specific route", "string"},
-		{"__debug", "Print debug information", "bool"},
-		{"__spew.req", "Spew the request", "bool"},
-		{"__spew.resp", "Spew the response", "bool"},
-		{"__file", "Write the response to a file", "string"},
-		{"__file.mode", "File mode (json, spew)", "string"},
+func (r *ApiExecExecRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The ID of the route to execute", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+		{Name: "__debug", Description: "Print debug information", Type: router.ArgTypeBool},
+		{Name: "__spew.req", Description: "Spew the request", Type: router.ArgTypeBool},
+		{Name: "__spew.resp", Description: "Spew the response", Type: router.ArgTypeBool},
+		{Name: "__file", Description: "Write the response to a file", Type: router.ArgTypeString},
+		{Name: "__file.mode", Description: "File mode", Type: router.ArgTypeString, Default: "json", Enum: []string{"json", "spew"}},
+		{Name: "__body", Description: "Load the request from a JSON or YAML file (@file) or stdin (@-). Other arguments override its keys", Type: router.ArgTypeString},
+		{Name: "__no_validate", Description: "Send settings operations without validating their fields first, e.g. to check that the server rejects invalid fields", Type: router.ArgTypeBool},
+		{Name: "__strict", Description: "Check the raw response for unknown fields, missing fields and type mismatches", Type: router.ArgTypeBool},
+		{Name: "__export", Description: "Print the request as a curl or HTTPie command or a Go program instead of sending it", Type: router.ArgTypeString, Enum: exportFormats},
+		{Name: "__export.secrets", Description: "Include the session token in exports instead of reading it from $" + tokenEnv, Type: router.ArgTypeBool},
+		{Name: "__export.run", Description: "Send the request after exporting it", Type: router.ArgTypeBool},
+		{Name: "__view", Description: "How to show the response, tree opens it in a collapsible viewer", Type: router.ArgTypeString, Default: "json", Enum: []string{"json", "tree"}},
 	}
 }
 
+// Any argument that is not declared is a request field in KEY::TYPE=VALUE form
+func (r *ApiExecExecRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
 func (r *ApiExecExecRoute) Setup(state *state.State) error {
 	return nil
 }
@@ -70,58 +88,63 @@ func (r *ApiExecExecRoute) Render(state *state.State, args map[string]string) er
 		}
 	}
 
-	show, ok := args["route"]
+	route, mkMap, err := buildRequest(state, args)
 
-	if !ok {
-		return fmt.Errorf("no route specified")
+	if err != nil {
+		return err
 	}
 
-	// Print detailed information about a specific route
-	var route api.TestableRoute
+	export := args["__export"]
+	run := export == "" || args["__export.run"] == "true"
 
-	for _, r := range api.GetTestableRoutes() {
-		if r.ID() == show {
-			route = r
-			break
-		}
+	if run {
+		fmt.Println("Route ID:", route.ID())
+		fmt.Println("Route Req Send:")
+		fmt.Println(structstring.SpewStruct(mkMap))
 	}
 
-	if route == nil {
-		return fmt.Errorf("route %s not found", show)
+	// Create the reqtype
+	populated, err := route.PopulateWithArgs(mkMap)
+
+	if err != nil {
+		return fmt.Errorf("failed to populate route with args: %w", err)
 	}
 
-	mkMap := make(map[string]any)
-	for k, v := range args {
-		if k == "route" || strings.HasPrefix(k, "__") {
-			continue
-		}
+	route = populated
 
-		// Handle types
-		kSplit := strings.Split(k, "::")
+	ctx := execContext(args)
 
-		if len(kSplit) != 2 {
-			kSplit = append(kSplit, "string")
-		}
+	if export != "" {
+		var req *fetch.Request
 
-		setKey := kSplit[0]
-		keyTyp := kSplit[1]
+		exportCtx := fetch.WithInterceptor(ctx, func(r *fetch.Request) bool {
+			if req == nil {
+				req = r
+			}
 
-		err := setValue(setKey, keyTyp, v, mkMap)
+			return false
+		})
 
-		if err != nil {
-			return err
+		// Build the request exactly as it would be sent without sending it
+		_, err := route.Exec(exportCtx, state)
+
+		if req == nil {
+			if err != nil {
+				return fmt.Errorf("failed to build request: %w", err)
+			}
+
+			return fmt.Errorf("%w: %s", ErrNoRequest, route.ID())
 		}
-	}
 
-	fmt.Println("Route ID:", route.ID())
-	fmt.Println("Route Req Send:")
-	fmt.Println(structstring.SpewStruct(mkMap))
+		if err := printExport(state, route, mkMap, req, export, args["__export.secrets"] == "true"); err != nil {
+			return err
+		}
 
-	// Create the reqtype
-	route, err := route.PopulateWithArgs(mkMap)
+		if !run {
+			return nil
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to populate route with args: %w", err)
+		fmt.Println()
 	}
 
 	// Print the request
@@ -129,19 +152,38 @@ func (r *ApiExecExecRoute) Render(state *state.State, args map[string]string) er
 		fmt.Println(structstring.SpewStruct(route))
 	}
 
-	// Send the request
-	resp, err := route.Exec(context.TODO(), state)
+	// Send the request, recording the raw response in strict mode
+	rec := &fetch.Recorder{}
+
+	strict := args["__strict"] == "true"
+
+	if strict {
+		ctx = fetch.WithRecorder(ctx, rec)
+	}
+
+	resp, err := route.Exec(ctx, state)
+
+	var driftErr error
+
+	if strict {
+		driftErr = strictCheck(route, rec)
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to execute route: %w", err)
 	}
 
+	if method := route.Meta().Method; state.StateFetchOptions.DryRun && method != "GET" && method != "HEAD" {
+		fmt.Println("Route Resp Recv: not sent (dry run)")
+		return driftErr
+	}
+
 	fmt.Println("Route Resp Recv:")
 
 	// Print the response
 	if spewResp, ok := args["__spew.resp"]; ok && spewResp == "true" {
 		fmt.Println(structstring.SpewStruct(resp))
-		return nil
+		return driftErr
 	}
 
 	// If __file is set, write to file
@@ -183,7 +225,21 @@ func (r *ApiExecExecRoute) Render(state *state.State, args map[string]string) er
 			return fmt.Errorf("unsupported mode %s", mode)
 		}
 
-		return nil
+		return driftErr
+	}
+
+	if args["__view"] == "tree" {
+		tree, err := jsontree.FromValue(resp)
+
+		if err != nil {
+			return fmt.Errorf("failed to convert response to JSON: %w", err)
+		}
+
+		if err := jsonview.Run(state, route.ID(), tree); err != nil {
+			return err
+		}
+
+		return driftErr
 	}
 
 	// Otherwise, convert to JSON
@@ -195,9 +251,134 @@ func (r *ApiExecExecRoute) Render(state *state.State, args map[string]string) er
 
 	fmt.Println(string(respJSON))
 
+	return driftErr
+}
+
+// Prints the drift report of the recorded response, returning contract.ErrDrift if the response does not match
+func strictCheck(route api.TestableRoute, rec *fetch.Recorder) error {
+	ex := rec.Last()
+
+	if ex == nil {
+		fmt.Println("Strict: no request was made, nothing to check")
+		return nil
+	}
+
+	report := contract.CheckExchange(route.ID(), ex, reflect.TypeOf(route.RespType()))
+
+	fmt.Println("Strict Check:")
+	report.Print(os.Stdout)
+
+	if report.Status == contract.StatusDrift {
+		return fmt.Errorf("%w: %d issue(s)", contract.ErrDrift, len(report.Issues))
+	}
+
 	return nil
 }
 
+// Executes the route, returning the response instead of printing it
+func (r *ApiExecExecRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	route, mkMap, err := buildRequest(state, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	route, err = route.PopulateWithArgs(mkMap)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to populate route with args: %w", err)
+	}
+
+	resp, err := route.Exec(execContext(args), state)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute route: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Returns the context to execute the route with
+func execContext(args map[string]string) context.Context {
+	if args["__no_validate"] == "true" {
+		return guilds.WithoutValidation(context.TODO())
+	}
+
+	return context.TODO()
+}
+
+// Finds the route to execute and parses the request fields out of args
+func buildRequest(state *state.State, args map[string]string) (api.TestableRoute, map[string]any, error) {
+	show, ok := args["route"]
+
+	if !ok {
+		return nil, nil, fmt.Errorf("no route specified")
+	}
+
+	route := api.GetTestableRoute(show)
+
+	if route == nil {
+		return nil, nil, fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, show)
+	}
+
+	mkMap, err := ParseRequestArgs(state, args)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return route, mkMap, nil
+}
+
+// ParseRequestArgs parses the request fields out of args. Arguments starting with __ and the route argument are skipped
+//
+// If __body is set, the request is first loaded from that file. Values may be @file/@- references and
+// contain ${...} placeholders, see Input
+func ParseRequestArgs(state *state.State, args map[string]string) (map[string]any, error) {
+	in := NewInput(state)
+
+	mkMap := make(map[string]any)
+
+	if body, ok := args["__body"]; ok && body != "" {
+		var err error
+		mkMap, err = in.Body(body)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for k, v := range args {
+		if k == "route" || strings.HasPrefix(k, "__") {
+			continue
+		}
+
+		v, err := in.Value(v)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+
+		// Handle types
+		kSplit := strings.Split(k, "::")
+
+		if len(kSplit) != 2 {
+			kSplit = append(kSplit, "string")
+		}
+
+		setKey := kSplit[0]
+		keyTyp := kSplit[1]
+
+		err = setValue(setKey, keyTyp, v, mkMap)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mkMap, nil
+}
+
 // Format for KV's are as follows:
 //
 // KEY::TYPE=VALUE for normal values
@@ -462,22 +643,35 @@ func (r *ApiExecExecRoute) Completion(state *state.State, line string, args map[
 	if len(completionRoutes) == 1 {
 		// Case #2: Only one completion means we have gotten to a full non-partial route
 		// Move on to stage 2 completions
-		return r.stage2Completion(line, args, completionRoutes[0])
+		return r.stage2Completion(state, line, args, completionRoutes[0])
 	}
 
 	return completions, nil
 }
 
 // Stage 2 completion occurs when the user has fully typed out a route, at this point, we return the whole line combined with request options in reqType
-func (r *ApiExecExecRoute) stage2Completion(line string, args map[string]string, route api.TestableRoute) (c []string, err error) {
-	// Case 1: In the middle of typing out an argument
+//
+// Declared arguments are completed from the route's argument spec, only the request fields are completed here
+func (r *ApiExecExecRoute) stage2Completion(state *state.State, line string, args map[string]string, route api.TestableRoute) (c []string, err error) {
+	// Case 1: In the middle of typing out a value. Request field values are left to the user
+	if name, _, ok := router.TypingValue(line); ok {
+		if r.isDeclaredArg(name) {
+			return router.CompleteArgs(r, state, line, args)
+		}
+		return
+	}
+
 	argsStr := strings.Replace(line, "apiexec.exec "+route.ID(), "", 1)
 
-	// Check if the user is at an '=' sign. This means that we should not provide completions at all as they want to type out a value
-	lastArg := shell.UtilFindLastArgInArgStr(argsStr)
+	// CompleteArgs would read the positional route as an untyped argument, so complete without it and add it back
+	declared, err := router.CompleteArgs(r, state, "apiexec.exec"+argsStr, args)
 
-	if strings.HasSuffix(lastArg, "=") {
-		return
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range declared {
+		c = append(c, strings.Replace(d, "apiexec.exec", "apiexec.exec "+route.ID(), 1))
 	}
 
 	reqType := route.ReqType()
@@ -545,3 +739,18 @@ func (r *ApiExecExecRoute) stage2Completion(line string, args map[string]string,
 
 	return
 }
+
+// Returns whether name is one of the route's declared arguments rather than a request field
+func (r *ApiExecExecRoute) isDeclaredArg(name string) bool {
+	if name == router.DryRunArgument.Name {
+		return true
+	}
+
+	for _, a := range r.Arguments() {
+		if a.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
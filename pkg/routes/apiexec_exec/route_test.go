@@ -0,0 +1,46 @@
+package apiexec_exec
+
+import (
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldsRoute struct {
+	testRoute
+}
+
+func (r *fieldsRoute) ReqType() any {
+	return &struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+	}{}
+}
+
+func TestStage2Completion(t *testing.T) {
+	r := &ApiExecExecRoute{}
+	s := &state.State{}
+	route := &fieldsRoute{}
+	args := map[string]string{"route": route.ID()}
+
+	c, err := r.stage2Completion(s, "apiexec.exec testRoute ", args, route)
+	assert.NoError(t, err)
+	assert.Contains(t, c, "apiexec.exec testRoute __export=")
+	assert.Contains(t, c, "apiexec.exec testRoute __view=")
+	assert.Contains(t, c, "apiexec.exec testRoute name=")
+	assert.Contains(t, c, "apiexec.exec testRoute enabled::bool=")
+
+	c, err = r.stage2Completion(s, "apiexec.exec testRoute __str", args, route)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apiexec.exec testRoute __strict="}, c)
+
+	c, err = r.stage2Completion(s, "apiexec.exec testRoute __view=", args, route)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apiexec.exec testRoute __view=json", "apiexec.exec testRoute __view=tree"}, c)
+
+	// Request field values are typed by the user
+	c, err = r.stage2Completion(s, "apiexec.exec testRoute name=", args, route)
+	assert.NoError(t, err)
+	assert.Empty(t, c)
+}
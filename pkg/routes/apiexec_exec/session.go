@@ -0,0 +1,52 @@
+package apiexec_exec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+)
+
+// How long sessions created from a token are considered valid for
+const TokenSessionLifetime = time.Hour
+
+// Returns a session for a token argument, which may be a placeholder such as ${env:TOKEN} or an @file reference
+func TokenSession(s *state.State, token string) (*types.CreateUserSessionResponse, error) {
+	token, err := NewInput(s).Value(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.CreateUserSessionResponse{Token: token, Expiry: time.Now().Add(TokenSessionLifetime)}, nil
+}
+
+// Returns the state to send route to instance with (the current instance if empty), using sess if it is not nil
+//
+// Otherwise the current session is only sent to the current instance, so that it is never leaked to an instance
+// it was not created on. Routes needing a session on other instances are an error suggesting to set tokenArg
+func TargetState(s *state.State, route api.TestableRoute, instance string, sess *types.CreateUserSessionResponse, tokenArg string) (*state.State, error) {
+	instance = strings.TrimSuffix(instance, "/")
+
+	if instance == "" {
+		instance = s.StateFetchOptions.InstanceAPIUrl
+	}
+
+	if sess != nil {
+		return s.WithInstance(instance, sess), nil
+	}
+
+	if instance == strings.TrimSuffix(s.StateFetchOptions.InstanceAPIUrl, "/") {
+		return s, nil
+	}
+
+	if route.Meta().Auth {
+		return nil, fmt.Errorf("%w: %s needs a session and the current session is not sent to %s, set %s to use one there", router.ErrInvalidArgument, route.ID(), instance, tokenArg)
+	}
+
+	return s.WithInstance(instance), nil
+}
@@ -0,0 +1,30 @@
+package apiexec_exec
+
+import (
+	"fmt"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+// Returns an error unless route may be sent repeatedly, e.g. when fuzzing it (action is "fuzz" for error messages).
+// Routes that are not GET requests may modify data so allowWrites must be set, and are refused in dry-run mode as
+// none of their responses would be received
+func CheckRepeatedWrites(s *state.State, route api.TestableRoute, allowWrites bool, action string) error {
+	method := route.Meta().Method
+
+	if method == "GET" || method == "HEAD" {
+		return nil
+	}
+
+	if !allowWrites {
+		return fmt.Errorf("%w: %s is a %s route and may modify data, set allow_writes=true to %s it anyways", router.ErrInvalidArgument, route.ID(), method, action)
+	}
+
+	if s.StateFetchOptions.DryRun {
+		return fmt.Errorf("%w: dry-run mode is on so %s requests are not sent, turn it off with dryrun enabled=false to %s %s", router.ErrInvalidArgument, method, action, route.ID())
+	}
+
+	return nil
+}
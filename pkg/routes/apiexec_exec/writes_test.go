@@ -0,0 +1,47 @@
+package apiexec_exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRoute struct {
+	meta api.RouteMeta
+}
+
+func (r *testRoute) ID() string { return "testRoute" }
+
+func (r *testRoute) PopulateWithArgs(args map[string]any) (api.TestableRoute, error) { return r, nil }
+
+func (r *testRoute) ReqType() any { return &struct{}{} }
+
+func (r *testRoute) RespType() any { return nil }
+
+func (r *testRoute) Exec(ctx context.Context, state *state.State) (any, error) { return nil, nil }
+
+func (r *testRoute) Meta() api.RouteMeta { return r.meta }
+
+func TestCheckRepeatedWrites(t *testing.T) {
+	s := &state.State{}
+
+	get := &testRoute{meta: api.RouteMeta{Method: "GET", Path: "/things"}}
+	post := &testRoute{meta: api.RouteMeta{Method: "POST", Path: "/things"}}
+
+	assert.NoError(t, CheckRepeatedWrites(s, get, false, "benchmark"))
+
+	err := CheckRepeatedWrites(s, post, false, "benchmark")
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+	assert.ErrorContains(t, err, "set allow_writes=true to benchmark it")
+
+	assert.NoError(t, CheckRepeatedWrites(s, post, true, "benchmark"))
+
+	// Nothing would be sent in dry-run mode, but reads still are
+	s.StateFetchOptions.DryRun = true
+	assert.NoError(t, CheckRepeatedWrites(s, get, false, "benchmark"))
+	assert.ErrorContains(t, CheckRepeatedWrites(s, post, true, "benchmark"), "dry-run mode is on")
+}
@@ -0,0 +1,295 @@
+package apiexec_fanout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/api/users"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+)
+
+// The request field the guild ID is set on
+const guildIdKey = "path:guildId"
+
+// How long summaries of responses are in the result table
+const summaryLength = 60
+
+// The result of running the route against a single guild
+type GuildResult struct {
+	GuildID   string `json:"guild_id"`
+	GuildName string `json:"guild_name,omitempty"`
+	Success   bool   `json:"success"`
+	ErrorType string `json:"error_type,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Response  any    `json:"response,omitempty"`
+}
+
+type ApiExecFanoutRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *ApiExecFanoutRoute) Command() string {
+	return "apiexec.fanout"
+}
+
+func (r *ApiExecFanoutRoute) Description() string {
+	return "Executes a route once per guild, showing a table of the results"
+}
+
+func (r *ApiExecFanoutRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The ID of the route to execute. Must have a path:guildId field", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+		{Name: "guilds", Description: "Comma-separated guild IDs, or all for every guild the bot is in", Type: router.ArgTypeString, Default: "all"},
+		{Name: "concurrency", Description: "How many guilds to run at once", Type: router.ArgTypeInt, Default: "4"},
+		{Name: "refresh", Description: "Whether to refresh the guild list", Type: router.ArgTypeBool, Default: "false"},
+	}
+}
+
+// Any argument that is not declared is a request field in KEY::TYPE=VALUE form, sent for every guild
+func (r *ApiExecFanoutRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *ApiExecFanoutRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *ApiExecFanoutRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+func (r *ApiExecFanoutRoute) Render(state *state.State, args map[string]string) error {
+	results, err := r.fanout(state, args)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "GUILD\tNAME\tOK\tERROR TYPE\tSUMMARY")
+
+	var failed int
+	for _, res := range results {
+		summary := res.Error
+
+		if res.Success {
+			summary = summarize(res.Response)
+		} else {
+			failed++
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", res.GuildID, res.GuildName, strconv.FormatBool(res.Success), res.ErrorType, summary)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d of %d guild(s) succeeded\n", len(results)-failed, len(results))
+
+	if failed > 0 {
+		return fmt.Errorf("route failed in %d guild(s)", failed)
+	}
+
+	return nil
+}
+
+func (r *ApiExecFanoutRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return r.fanout(state, args)
+}
+
+// Returns the guilds to run the route in along with their names
+func (r *ApiExecFanoutRoute) resolveGuilds(state *state.State, guildsArg string, refresh bool) ([]string, map[string]string, error) {
+	guilds, err := users.GetUserGuilds(r.ctx, state, &users.GetUserGuildsData{Refresh: refresh})
+	return selectGuilds(guildsArg, guilds, err)
+}
+
+// Selects the guilds given by the guilds argument from the user's guilds, which failed to be fetched if err is set
+func selectGuilds(guildsArg string, guilds *types.DashboardGuildData, err error) ([]string, map[string]string, error) {
+	names := map[string]string{}
+
+	if guildsArg != "all" {
+		if err != nil {
+			// Names are only cosmetic when guilds are given explicitly
+			slog.Warn("Failed to fetch guild names", slog.String("err", err.Error()))
+		} else {
+			for _, g := range guilds.Guilds {
+				names[g.ID] = g.Name
+			}
+		}
+
+		var ids []string
+		for _, id := range strings.Split(guildsArg, ",") {
+			if id = strings.TrimSpace(id); id != "" && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+
+		if len(ids) == 0 {
+			return nil, nil, fmt.Errorf("%w: guilds: no guild IDs given", router.ErrInvalidArgument)
+		}
+
+		return ids, names, nil
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch guilds: %w", err)
+	}
+
+	var ids []string
+	for _, g := range guilds.Guilds {
+		if slices.Contains(guilds.BotInGuilds, g.ID) {
+			ids = append(ids, g.ID)
+			names[g.ID] = g.Name
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil, errors.New("the bot is not in any of your guilds")
+	}
+
+	return ids, names, nil
+}
+
+func (r *ApiExecFanoutRoute) fanout(state *state.State, args map[string]string) ([]GuildResult, error) {
+	route := api.GetTestableRoute(args["route"])
+
+	if route == nil {
+		return nil, fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, args["route"])
+	}
+
+	var hasGuildField bool
+	for _, f := range api.RequestFields(reflect.TypeOf(route.ReqType())) {
+		if f.Key == guildIdKey {
+			hasGuildField = true
+			break
+		}
+	}
+
+	if !hasGuildField {
+		return nil, fmt.Errorf("%w: route %s does not take a %s field", router.ErrInvalidArgument, route.ID(), guildIdKey)
+	}
+
+	concurrency, err := strconv.Atoi(args["concurrency"])
+
+	if err != nil || concurrency < 1 {
+		return nil, fmt.Errorf("%w: concurrency must be at least 1", router.ErrInvalidArgument)
+	}
+
+	// Strip the fanout arguments before parsing the request fields
+	reqArgs := map[string]string{}
+	for k, v := range args {
+		if k == "guilds" || k == "concurrency" || k == "refresh" {
+			continue
+		}
+
+		reqArgs[k] = v
+	}
+
+	fields, err := apiexec_exec.ParseRequestArgs(state, reqArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	guildIds, names, err := r.resolveGuilds(state, args["guilds"], args["refresh"] == "true")
+
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Running route in guilds", slog.String("route", route.ID()), slog.Int("guilds", len(guildIds)), slog.Int("concurrency", concurrency))
+
+	// Settings operations are validated against the same modules in every guild
+	ctx := guilds.WithModulesCache(r.ctx)
+
+	results := make([]GuildResult, len(guildIds))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, guildId := range guildIds {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = r.runInGuild(ctx, state, route, fields, guildId)
+			results[i].GuildName = names[guildId]
+		}()
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+func (r *ApiExecFanoutRoute) runInGuild(ctx context.Context, state *state.State, route api.TestableRoute, fields map[string]any, guildId string) GuildResult {
+	res := GuildResult{GuildID: guildId}
+
+	guildFields := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		guildFields[k] = v
+	}
+
+	guildFields[guildIdKey] = guildId
+
+	populated, err := route.PopulateWithArgs(guildFields)
+
+	if err != nil {
+		res.ErrorType = "invalid_request"
+		res.Error = err.Error()
+		return res
+	}
+
+	resp, err := populated.Exec(ctx, state)
+
+	if err != nil {
+		res.ErrorType = fetch.ErrorType(err)
+		res.Error = apiexec_exec.Truncate(apiexec_exec.FirstLine(err.Error()), summaryLength)
+		return res
+	}
+
+	res.Success = true
+	res.Response = resp
+
+	return res
+}
+
+// Returns a single-line summary of a response
+func summarize(resp any) string {
+	b, err := json.Marshal(resp)
+
+	if err != nil {
+		return fmt.Sprintf("%v", resp)
+	}
+
+	return apiexec_exec.Truncate(string(b), summaryLength)
+}
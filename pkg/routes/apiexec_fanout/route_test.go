@@ -0,0 +1,61 @@
+package apiexec_fanout
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var testGuilds = &types.DashboardGuildData{
+	Guilds: []*types.DashboardGuild{
+		{ID: "1", Name: "One"},
+		{ID: "2", Name: "Two"},
+		{ID: "3", Name: "Three"},
+	},
+	BotInGuilds: []string{"3", "1"},
+}
+
+func TestSelectGuilds(t *testing.T) {
+	// all selects the guilds the bot is in, in the order of the user's guilds
+	ids, names, err := selectGuilds("all", testGuilds, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "3"}, ids)
+	assert.Equal(t, map[string]string{"1": "One", "3": "Three"}, names)
+
+	_, _, err = selectGuilds("all", nil, errors.New("unauthorized"))
+	assert.ErrorContains(t, err, "failed to fetch guilds: unauthorized")
+
+	_, _, err = selectGuilds("all", &types.DashboardGuildData{Guilds: testGuilds.Guilds}, nil)
+	assert.ErrorContains(t, err, "the bot is not in any of your guilds")
+
+	// Given guilds are used as is, deduplicated, whether or not the bot is in them
+	ids, names, err = selectGuilds(" 2, 4,2,,", testGuilds, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "4"}, ids)
+	assert.Equal(t, "Two", names["2"])
+
+	// Names are optional when guilds are given
+	ids, names, err = selectGuilds("2", nil, errors.New("unauthorized"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, ids)
+	assert.Empty(t, names)
+
+	_, _, err = selectGuilds(" , ", testGuilds, nil)
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+}
+
+func TestSummarize(t *testing.T) {
+	assert.Equal(t, `{"id":"1","name":"One","avatar":"","permissions":0}`, summarize(testGuilds.Guilds[0]))
+	assert.Equal(t, "null", summarize(nil))
+
+	long := summarize(map[string]string{"a": strings.Repeat("x", 100)})
+	assert.Len(t, []rune(long), summaryLength)
+	assert.True(t, strings.HasSuffix(long, "…"))
+
+	// Values that cannot be marshalled are printed as is
+	assert.Equal(t, "(1+2i)", summarize(complex(1, 2)))
+}
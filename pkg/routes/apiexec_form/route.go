@@ -0,0 +1,212 @@
+package apiexec_form
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/pkg/tui"
+	"github.com/anti-raid/evil-befall/pkg/tui/structform"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const responsePage = "response"
+
+const (
+	formHelp     = "Enter on a field without a text cursor opens it, Esc goes back. Required fields are marked with *"
+	responseHelp = "Arrow keys/PgUp/PgDn scroll, Esc goes back to the form, q quits"
+)
+
+type ApiExecFormRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *ApiExecFormRoute) Command() string {
+	return "apiexec.form"
+}
+
+func (r *ApiExecFormRoute) Description() string {
+	return "Fill in the request of a route using a form, then execute it"
+}
+
+func (r *ApiExecFormRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The ID of the route to execute", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+	}
+}
+
+func (r *ApiExecFormRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *ApiExecFormRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+func (r *ApiExecFormRoute) Render(state *state.State, args map[string]string) error {
+	route := api.GetTestableRoute(args["route"])
+
+	if route == nil {
+		return fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, args["route"])
+	}
+
+	app := tui.NewTview(state)
+	builder := structform.NewBuilder(app)
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(formHelp)
+
+	root := builder.Object(route.ID(), structform.RequestFields(reflect.TypeOf(route.ReqType())))
+
+	response := tview.NewTextView().SetScrollable(true).SetWrap(true)
+	response.SetBorder(true).SetTitle(" Response ")
+	builder.Pages.AddPage(responsePage, response, true, false)
+
+	builder.OnNavigate = func(page string) {
+		if page == responsePage {
+			help.SetText(responseHelp)
+		} else {
+			help.SetText(formHelp)
+		}
+	}
+
+	// The last response, printed once the form is closed so it stays in the terminal
+	var mu sync.Mutex
+	var lastOutput string
+
+	// In dry-run mode requests that would not be sent are shown once the form is closed, as they would otherwise
+	// be printed over the form
+	var dryRunFields map[string]any
+
+	var executing bool
+
+	root.Form.AddButton("Execute", func() {
+		if executing {
+			return
+		}
+
+		fields, err := root.Value()
+
+		if err != nil {
+			help.SetText("[red]" + tview.Escape(err.Error()))
+			return
+		}
+
+		if method := route.Meta().Method; state.StateFetchOptions.DryRun && method != "GET" && method != "HEAD" {
+			dryRunFields = fields
+			app.Stop()
+			return
+		}
+
+		executing = true
+		response.SetTitle(" Response ").SetTitleColor(tcell.ColorDefault)
+		response.SetText("Executing " + route.ID() + "...")
+		builder.Show(responsePage)
+
+		go func() {
+			output, ok := r.execute(state, route, fields)
+
+			mu.Lock()
+			lastOutput = output
+			mu.Unlock()
+
+			app.QueueUpdateDraw(func() {
+				executing = false
+
+				if ok {
+					response.SetTitle(" Response ").SetTitleColor(tcell.ColorGreen)
+				} else {
+					response.SetTitle(" Error ").SetTitleColor(tcell.ColorRed)
+				}
+
+				response.SetText(output).ScrollToBeginning()
+			})
+		}()
+	})
+
+	root.Form.AddButton("Quit", app.Stop)
+	root.Form.SetCancelFunc(app.Stop)
+
+	response.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			builder.Show(root.Page)
+			return nil
+		case event.Rune() == 'q':
+			app.Stop()
+			return nil
+		}
+
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(builder.Pages, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	builder.Pages.SwitchToPage(root.Page)
+
+	go func() {
+		<-r.ctx.Done()
+		app.Stop()
+	}()
+
+	if err := app.SetRoot(layout, true).Run(); err != nil {
+		return err
+	}
+
+	if dryRunFields != nil {
+		if output, ok := r.execute(state, route, dryRunFields); !ok {
+			return errors.New(output)
+		}
+
+		fmt.Println("Not sent (dry run)")
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if lastOutput != "" {
+		fmt.Println(lastOutput)
+	}
+
+	return nil
+}
+
+// Executes the route with the fields of the form, returning the indented response or the error
+func (r *ApiExecFormRoute) execute(state *state.State, route api.TestableRoute, fields map[string]any) (string, bool) {
+	populated, err := route.PopulateWithArgs(fields)
+
+	if err != nil {
+		return "failed to populate route with args: " + err.Error(), false
+	}
+
+	resp, err := populated.Exec(r.ctx, state)
+
+	if err != nil {
+		return "failed to execute route: " + err.Error(), false
+	}
+
+	b, err := json.MarshalIndent(resp, "", "  ")
+
+	if err != nil {
+		return "failed to convert response to JSON: " + err.Error(), false
+	}
+
+	return string(b), true
+}
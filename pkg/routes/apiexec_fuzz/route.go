@@ -0,0 +1,378 @@
+package apiexec_fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/fuzz"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+)
+
+var ErrFindings = errors.New("fuzzing found issues")
+
+// The declared arguments that are not request fields
+var fuzzArgs = []string{"instance", "token", "field", "out", "allow_writes", "delay"}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+
+// How long error messages are in the result table
+const messageLength = 60
+
+// Result is the result of sending a single case
+type Result struct {
+	Field        string       `json:"field"`
+	Case         string       `json:"case"`
+	ExpectReject bool         `json:"expect_reject"`
+	Outcome      fuzz.Outcome `json:"outcome"`
+	Status       int          `json:"status,omitempty"`
+	ErrorType    string       `json:"error_type,omitempty"`
+	Error        string       `json:"error,omitempty"`
+
+	// Why the result should be looked at, empty if it is fine
+	Finding string `json:"finding,omitempty"`
+
+	// The apiexec.exec __body file reproducing the request, only saved for findings
+	Reproducer string `json:"reproducer,omitempty"`
+}
+
+// Report is the result of fuzzing a route, saved as report.json in the output directory
+type Report struct {
+	Route    string   `json:"route"`
+	Instance string   `json:"instance"`
+	Baseline Result   `json:"baseline"`
+	Results  []Result `json:"results"`
+	Findings int      `json:"findings"`
+}
+
+type ApiExecFuzzRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *ApiExecFuzzRoute) Command() string {
+	return "apiexec.fuzz"
+}
+
+func (r *ApiExecFuzzRoute) Description() string {
+	return "Sends boundary and malformed values for each request field of a route, flagging server errors and unexpected successes"
+}
+
+func (r *ApiExecFuzzRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The ID of the route to fuzz", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+		{Name: "instance", Description: "The instance URL to send requests to", Type: router.ArgTypeString, DefaultFunc: currentInstance, DefaultHelp: "[current instance]"},
+		{Name: "token", Description: "A token to use instead of the current session, e.g. ${env:STAGING_TOKEN}. The current session is only sent to the current instance", Type: router.ArgTypeString},
+		{Name: "field", Description: "Only fuzz fields starting with this path, e.g. body.fields", Type: router.ArgTypeString},
+		{Name: "out", Description: "The directory to save reproducers and the report to", Type: router.ArgTypeString, Default: "fuzz-results"},
+		{Name: "allow_writes", Description: "Allow fuzzing routes that are not GET requests. These may modify data on the instance", Type: router.ArgTypeBool, Default: "false"},
+		{Name: "delay", Description: "How long to wait between requests", Type: router.ArgTypeDuration, Default: "0s"},
+	}
+}
+
+// Any argument that is not declared is a request field in KEY::TYPE=VALUE form. These are the valid values
+// used for all fields that are not being fuzzed
+func (r *ApiExecFuzzRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *ApiExecFuzzRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *ApiExecFuzzRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+func currentInstance(state *state.State) string {
+	return state.StateFetchOptions.InstanceAPIUrl
+}
+
+func (r *ApiExecFuzzRoute) Render(state *state.State, args map[string]string) error {
+	report, err := r.fuzz(state, args, true)
+
+	if err != nil {
+		return err
+	}
+
+	if report.Findings > 0 {
+		return fmt.Errorf("%w: %d finding(s), see %s", ErrFindings, report.Findings, args["out"])
+	}
+
+	return nil
+}
+
+func (r *ApiExecFuzzRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return r.fuzz(state, args, false)
+}
+
+func (r *ApiExecFuzzRoute) fuzz(s *state.State, args map[string]string, print bool) (*Report, error) {
+	route := api.GetTestableRoute(args["route"])
+
+	if route == nil {
+		return nil, fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, args["route"])
+	}
+
+	if err := apiexec_exec.CheckRepeatedWrites(s, route, args["allow_writes"] == "true", "fuzz"); err != nil {
+		return nil, err
+	}
+
+	delay, err := time.ParseDuration(args["delay"])
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: delay: %w", router.ErrInvalidArgument, err)
+	}
+
+	reqArgs := map[string]string{}
+	for k, v := range args {
+		if !slices.Contains(fuzzArgs, k) {
+			reqArgs[k] = v
+		}
+	}
+
+	base, err := apiexec_exec.ParseRequestArgs(s, reqArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := targetState(s, route, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []fuzz.Case
+	for _, c := range fuzz.Cases(reflect.TypeOf(route.ReqType())) {
+		if strings.HasPrefix(c.Field, args["field"]) {
+			cases = append(cases, c)
+		}
+	}
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("%w: route %s has no request fields to fuzz matching %q", router.ErrInvalidArgument, route.ID(), args["field"])
+	}
+
+	outDir := filepath.Join(args["out"], unsafeFileChars.ReplaceAllString(route.ID(), "_"))
+
+	report := &Report{
+		Route:    route.ID(),
+		Instance: target.StateFetchOptions.InstanceAPIUrl,
+		Baseline: r.send(target, route, fuzz.Case{Field: "(baseline)", Name: "baseline"}, base),
+	}
+
+	if print {
+		fmt.Printf("Fuzzing %s on %s with %d case(s)\n", route.ID(), report.Instance, len(cases))
+		fmt.Printf("Baseline: %s %s\n\n", report.Baseline.Outcome, statusString(report.Baseline.Status))
+
+		if report.Baseline.Outcome != fuzz.OutcomeAccepted {
+			fmt.Println(ansi.Color(ansi.Yellow, "The baseline request was not accepted, set valid values for the other fields so that rejections come from the fuzzed field: "+report.Baseline.Error))
+			fmt.Println()
+		}
+	}
+
+	for i, c := range cases {
+		if i > 0 && delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.ctx.Done():
+				return nil, r.ctx.Err()
+			}
+		}
+
+		fields := fuzz.Apply(base, c)
+		res := r.send(target, route, c, fields)
+
+		if res.Finding != "" {
+			res.Reproducer, err = saveReproducer(outDir, i+1, c, fields)
+
+			if err != nil {
+				return nil, err
+			}
+
+			report.Findings++
+		}
+
+		report.Results = append(report.Results, res)
+	}
+
+	if err := saveReport(outDir, report); err != nil {
+		return nil, err
+	}
+
+	if print {
+		printReport(route, report)
+	}
+
+	return report, nil
+}
+
+// Returns the state to send requests with, see apiexec_exec.TargetState
+func targetState(s *state.State, route api.TestableRoute, args map[string]string) (*state.State, error) {
+	var sess *types.CreateUserSessionResponse
+
+	if token := args["token"]; token != "" {
+		var err error
+		sess, err = apiexec_exec.TokenSession(s, token)
+
+		if err != nil {
+			return nil, fmt.Errorf("token: %w", err)
+		}
+	}
+
+	return apiexec_exec.TargetState(s, route, args["instance"], sess, "token")
+}
+
+// Sends a single case, classifying the response
+func (r *ApiExecFuzzRoute) send(s *state.State, route api.TestableRoute, c fuzz.Case, fields map[string]any) Result {
+	res := Result{Field: c.Field, Case: c.Name, ExpectReject: c.ExpectReject}
+
+	populated, err := route.PopulateWithArgs(fields)
+
+	if err != nil {
+		res.Outcome = fuzz.OutcomeClientRejected
+		res.Error = err.Error()
+		return res
+	}
+
+	rec := &fetch.Recorder{}
+
+	// Fields are sent as given, as the server must reject invalid ones itself
+	_, err = populated.Exec(fetch.WithRecorder(guilds.WithoutValidation(r.ctx), rec), s)
+
+	if ex := rec.Last(); ex != nil {
+		res.Status = ex.Status
+		res.ErrorType = ex.ErrorType
+	}
+
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	res.Outcome = fuzz.Classify(res.Status, err)
+	res.Finding = c.Finding(res.Outcome)
+
+	return res
+}
+
+// Saves the fields of a case as an apiexec.exec __body file, returning its path
+func saveReproducer(dir string, n int, c fuzz.Case, fields map[string]any) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reproducer directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%03d-%s-%s.json", n, unsafeFileChars.ReplaceAllString(c.Field, "_"), c.Name)
+	path := filepath.Join(dir, name)
+
+	b, err := json.MarshalIndent(fields, "", "  ")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to encode reproducer: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write reproducer: %w", err)
+	}
+
+	return path, nil
+}
+
+func saveReport(dir string, report *Report) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+func printReport(route api.TestableRoute, report *Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "FIELD\tCASE\tOUTCOME\tSTATUS\tERROR TYPE\tFINDING")
+
+	counts := map[fuzz.Outcome]int{}
+
+	for _, res := range report.Results {
+		counts[res.Outcome]++
+
+		finding := res.Finding
+		if finding != "" {
+			finding = ansi.Color(ansi.Red, finding)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", res.Field, res.Case, res.Outcome, statusString(res.Status), res.ErrorType, finding)
+	}
+
+	//nolint:errcheck
+	w.Flush()
+
+	fmt.Printf(
+		"\n%d case(s): %d accepted, %d rejected, %d rejected before sending, %d server error(s), %d without response\n",
+		len(report.Results),
+		counts[fuzz.OutcomeAccepted],
+		counts[fuzz.OutcomeRejected],
+		counts[fuzz.OutcomeClientRejected],
+		counts[fuzz.OutcomeServerError],
+		counts[fuzz.OutcomeTransportError],
+	)
+
+	if report.Findings == 0 {
+		fmt.Println(ansi.Color(ansi.Green, "No findings"))
+		return
+	}
+
+	fmt.Println(ansi.Color(ansi.BoldRed, fmt.Sprintf("%d finding(s). Reproduce them with:", report.Findings)))
+
+	for _, res := range report.Results {
+		if res.Reproducer != "" {
+			comment := res.Finding
+			if res.Error != "" {
+				comment += ": " + apiexec_exec.Truncate(apiexec_exec.FirstLine(res.Error), messageLength)
+			}
+
+			fmt.Printf("  apiexec.exec route=%s __body=@%s  # %s\n", route.ID(), res.Reproducer, comment)
+		}
+	}
+}
+
+func statusString(status int) string {
+	if status == 0 {
+		return "-"
+	}
+
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,82 @@
+package apiexec_fuzz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRoute struct {
+	meta api.RouteMeta
+}
+
+func (r *testRoute) ID() string { return "testRoute" }
+
+func (r *testRoute) PopulateWithArgs(args map[string]any) (api.TestableRoute, error) { return r, nil }
+
+func (r *testRoute) ReqType() any { return &struct{}{} }
+
+func (r *testRoute) RespType() any { return nil }
+
+func (r *testRoute) Exec(ctx context.Context, state *state.State) (any, error) { return nil, nil }
+
+func (r *testRoute) Meta() api.RouteMeta { return r.meta }
+
+func TestTargetState(t *testing.T) {
+	t.Setenv("EB_TEST_TOKEN", "staging-token")
+
+	s := &state.State{}
+	s.StateFetchOptions.InstanceAPIUrl = "http://localhost:3010"
+	s.StateFetchOptions.DryRun = true
+	s.Session.UserSessions = []*types.CreateUserSessionResponse{{UserID: "1", Token: "current-token"}}
+
+	authed := &testRoute{meta: api.RouteMeta{Method: "GET", Path: "/things", Auth: true}}
+	public := &testRoute{meta: api.RouteMeta{Method: "GET", Path: "/things"}}
+
+	// The current instance uses the current session
+	target, err := targetState(s, authed, map[string]string{"instance": "http://localhost:3010/"})
+	assert.NoError(t, err)
+	assert.Same(t, s, target)
+
+	// Other instances are never sent the current session
+	_, err = targetState(s, authed, map[string]string{"instance": "https://staging.example.com"})
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+
+	target, err = targetState(s, public, map[string]string{"instance": "https://staging.example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", target.StateFetchOptions.InstanceAPIUrl)
+	assert.Empty(t, target.Session.UserSessions)
+	assert.True(t, target.StateFetchOptions.DryRun)
+
+	target, err = targetState(s, authed, map[string]string{"instance": "https://staging.example.com", "token": "${env:EB_TEST_TOKEN}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", target.StateFetchOptions.InstanceAPIUrl)
+	assert.Equal(t, "staging-token", target.Session.PeekCurrentSession().Token)
+
+	// A token replaces the current session on the current instance too
+	target, err = targetState(s, authed, map[string]string{"token": "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:3010", target.StateFetchOptions.InstanceAPIUrl)
+	assert.Equal(t, "other", target.Session.PeekCurrentSession().Token)
+}
+
+func TestFuzzRefusesDryRunWrites(t *testing.T) {
+	route := &testRoute{meta: api.RouteMeta{Method: "POST", Path: "/things"}}
+	api.RegisterTestableRouteCategory(api.NewTestableRouteCategory("fuzz-tests", route))
+
+	s := &state.State{}
+	s.StateFetchOptions.DryRun = true
+
+	r := &ApiExecFuzzRoute{}
+	assert.NoError(t, r.Setup(s))
+	defer r.Destroy(s)
+
+	_, err := r.fuzz(s, map[string]string{"route": route.ID(), "allow_writes": "true", "delay": "0s"}, false)
+	assert.ErrorIs(t, err, router.ErrInvalidArgument)
+	assert.ErrorContains(t, err, "dry-run mode is on")
+}
@@ -0,0 +1,194 @@
+package apiexec_ls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/openapi"
+)
+
+// How many levels of nested structs are shown in schemas
+const maxSchemaDepth = 4
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	enumListType      = reflect.TypeOf((*interface{ List() []string })(nil)).Elem()
+)
+
+// Returns a short name for a type, e.g. string, []types.Status or orderedmap.OrderedMap[string,any]
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "-"
+	}
+
+	s := strings.ReplaceAll(t.String(), "interface {}", "any")
+
+	// Generic type arguments are printed with their full package path
+	return strings.NewReplacer(
+		"github.com/anti-raid/evil-befall/types/silverpelt.", "silverpelt.",
+		"github.com/anti-raid/evil-befall/types.", "types.",
+		"github.com/wk8/go-ordered-map/v2.", "orderedmap.",
+	).Replace(s)
+}
+
+// Returns the struct type a field expands into in a schema (looking through pointers, slices and maps), if any
+func structOf(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == timeType || t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return nil, false
+	}
+
+	return t, true
+}
+
+// Writes the properties of a struct type as tab separated NAME, TYPE, REQUIRED and DESCRIPTION rows, indenting
+// the properties of nested structs below their parent
+//
+// extra is inserted after the type column of every row so that nested rows line up with tables that have more columns
+func writeProperties(w io.Writer, t reflect.Type, extra string, depth int, seen map[reflect.Type]bool) {
+	t, ok := structOf(t)
+
+	if !ok || depth > maxSchemaDepth || seen[t] {
+		return
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	for _, p := range openapi.Properties(t) {
+		fmt.Fprintf(w, "%s%s\t%s\t%s%s\t%s\n", strings.Repeat("  ", depth), p.Name, typeString(p.Type), extra, yesNo(p.Required), p.Description)
+		writeProperties(w, p.Type, extra, depth+1, seen)
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+// Returns whether a route matches a case-insensitive search in its ID, path, description or request fields
+func matches(route api.TestableRoute, query string) bool {
+	query = strings.ToLower(query)
+	meta := route.Meta()
+
+	haystack := []string{route.ID(), meta.Path, meta.Description}
+
+	for _, f := range api.RequestFields(reflect.TypeOf(route.ReqType())) {
+		haystack = append(haystack, f.Key, f.Description)
+	}
+
+	for _, s := range haystack {
+		if strings.Contains(strings.ToLower(s), query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns an apiexec.exec command line calling the route with placeholders for its required fields
+func example(route api.TestableRoute) string {
+	line := "apiexec.exec route=" + route.ID()
+
+	for _, f := range api.RequestFields(reflect.TypeOf(route.ReqType())) {
+		if !f.Required() {
+			continue
+		}
+
+		t := f.Type
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch {
+		case f.Location == api.LocationPath || f.Location == api.LocationQuery:
+			line += fmt.Sprintf(" %s=<%s>", f.Key, f.Name)
+		case t.Kind() == reflect.Bool:
+			line += fmt.Sprintf(" %s::bool=false", f.Key)
+		case t.Kind() == reflect.String:
+			line += fmt.Sprintf(" %s=<%s>", f.Key, f.Name)
+		case t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64:
+			line += fmt.Sprintf(" %s::%s=0", f.Key, t.Kind())
+		default:
+			b, err := json.Marshal(skeleton(f.Type, 0))
+
+			if err != nil {
+				b = []byte("null")
+			}
+
+			line += fmt.Sprintf(" %s::json='%s'", f.Key, b)
+		}
+	}
+
+	return line
+}
+
+// Returns a JSON value of a type with only its required fields set to zero values (or the first value of enums)
+func skeleton(t reflect.Type, depth int) any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return time.Time{}.Format(time.RFC3339)
+	}
+
+	if t.Kind() == reflect.String && t.Implements(enumListType) {
+		if values := reflect.Zero(t).Interface().(interface{ List() []string }).List(); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj := map[string]any{}
+
+		if _, ok := structOf(t); !ok || depth > maxSchemaDepth {
+			return obj
+		}
+
+		for _, p := range openapi.Properties(t) {
+			if p.Required {
+				obj[p.Name] = skeleton(p.Type, depth+1)
+			}
+		}
+
+		return obj
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+
+		return []any{}
+	case reflect.Map:
+		return map[string]any{}
+	case reflect.String:
+		return ""
+	case reflect.Bool:
+		return false
+	case reflect.Interface:
+		return nil
+	}
+
+	if t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64 {
+		return 0
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package apiexec_ls
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestTypeString(t *testing.T) {
+	assert.Equal(t, "orderedmap.OrderedMap[string,any]", typeString(reflect.TypeOf(orderedmap.OrderedMap[string, any]{})))
+	assert.Equal(t, "[]*types.SettingsExecute", typeString(reflect.TypeOf([]*types.SettingsExecute{})))
+	assert.Equal(t, "-", typeString(nil))
+}
+
+func TestSkeleton(t *testing.T) {
+	type inner struct {
+		Name string `json:"name"`
+		Note string `json:"note,omitempty"`
+	}
+
+	type value struct {
+		Count  int            `json:"count"`
+		Inner  *inner         `json:"inner"`
+		Tags   []string       `json:"tags"`
+		Extra  map[string]any `json:"extra,omitempty"`
+		Fields orderedmap.OrderedMap[string, any]
+	}
+
+	assert.Equal(t, map[string]any{
+		"count":  0,
+		"inner":  map[string]any{"name": ""},
+		"tags":   []any{},
+		"Fields": map[string]any{},
+	}, skeleton(reflect.TypeOf(value{}), 0))
+}
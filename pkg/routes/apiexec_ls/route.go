@@ -2,30 +2,19 @@ package apiexec_ls
 
 import (
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/anti-raid/evil-befall/pkg/ansi"
 	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/router"
 	"github.com/anti-raid/evil-befall/pkg/state"
-	"github.com/anti-raid/spintrack/structstring"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-var ssCfg = structstring.NewDefaultConvertStructToStringConfig()
-
-func init() {
-	ssCfg.StructRecurseOverride = func(t reflect.Type) (*string, bool) {
-		switch t.PkgPath() {
-		case "github.com/wk8/go-ordered-map/v2":
-			omapVal := "orderedmap.OrderedMap"
-			return &omapVal, true
-		}
-
-		return structstring.BaseStructRecurseOverride(t)
-	}
-}
-
 type ApiExecLsRoute struct {
 }
 
@@ -37,9 +26,10 @@ func (r *ApiExecLsRoute) Description() string {
 	return "Lists all available API endpoints that can be tested"
 }
 
-func (r *ApiExecLsRoute) Arguments() [][3]string {
-	return [][3]string{
-		{"route", "Show detailed information about a specific route", "string"},
+func (r *ApiExecLsRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "Show detailed information about a specific route", Type: router.ArgTypeString, Completion: api.CompleteTestableRouteIDs},
+		{Name: "search", Description: "Only list routes whose ID, path, description or request fields contain this text", Type: router.ArgTypeString},
 	}
 }
 
@@ -55,8 +45,24 @@ func (r *ApiExecLsRoute) Render(state *state.State, args map[string]string) erro
 	show, ok := args["route"]
 
 	if !ok {
-		// Print all route ID's only
+		search := args["search"]
+		found := 0
+
 		for _, cat := range api.GetTestableRouteCategories() {
+			var routes []api.TestableRoute
+
+			for _, route := range cat.Routes {
+				if search == "" || matches(route, search) {
+					routes = append(routes, route)
+				}
+			}
+
+			if len(routes) == 0 {
+				continue
+			}
+
+			found += len(routes)
+
 			fmt.Println(cases.Title(language.English).String(cat.Name))
 
 			// Print 2x = for each character in the category name
@@ -68,35 +74,99 @@ func (r *ApiExecLsRoute) Render(state *state.State, args map[string]string) erro
 
 			fmt.Println(eqs)
 
-			for _, route := range cat.Routes {
-				fmt.Println(route.ID())
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+			for _, route := range routes {
+				meta := route.Meta()
+
+				auth := ""
+				if meta.Auth {
+					auth = "[auth]"
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", route.ID(), meta.Method, meta.Path, auth, meta.Description)
+			}
+
+			if err := w.Flush(); err != nil {
+				return err
 			}
 
 			fmt.Println()
 		}
 
+		if search != "" && found == 0 {
+			return fmt.Errorf("no routes match %q", search)
+		}
+
 		return nil
 	}
 
 	// Print detailed information about a specific route
-	var route api.TestableRoute
+	route := api.GetTestableRoute(show)
 
-	for _, r := range api.GetTestableRoutes() {
-		if r.ID() == show {
-			route = r
-			break
+	if route == nil {
+		return fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, show)
+	}
+
+	meta := route.Meta()
+
+	fmt.Println(ansi.Color(ansi.Cyan, route.ID()))
+	fmt.Printf("%s %s\n", meta.Method, meta.Path)
+
+	if meta.Auth {
+		fmt.Println("Auth: required (uses the current session)")
+	} else {
+		fmt.Println("Auth: not required")
+	}
+
+	if meta.Description != "" {
+		fmt.Println(meta.Description)
+	}
+
+	reqType := reflect.TypeOf(route.ReqType())
+	fields := api.RequestFields(reqType)
+
+	fmt.Println()
+
+	if len(fields) == 0 {
+		fmt.Println("Request: no fields")
+	} else {
+		fmt.Printf("Request (%s):\n", typeString(reqType))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  FIELD\tTYPE\tLOCATION\tREQUIRED\tDESCRIPTION")
+
+		for _, f := range fields {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", f.Key, typeString(f.Type), f.Location, yesNo(f.Required()), f.Description)
+			writeProperties(w, f.Type, "\t", 2, map[reflect.Type]bool{})
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
 		}
 	}
 
-	if route == nil {
-		return fmt.Errorf("route %s not found", show)
+	respType := reflect.TypeOf(route.RespType())
+
+	fmt.Println()
+
+	if _, ok := structOf(respType); !ok {
+		fmt.Printf("Response: %s\n", typeString(respType))
+	} else {
+		fmt.Printf("Response (%s):\n", typeString(respType))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  FIELD\tTYPE\tREQUIRED\tDESCRIPTION")
+		writeProperties(w, respType, "", 1, map[reflect.Type]bool{})
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
 	}
 
-	fmt.Println("Route ID:", route.ID())
-	fmt.Println("Route ReqType:")
-	fmt.Println(structstring.ConvertStructToString(route.ReqType(), ssCfg))
-	fmt.Println("Route RespType:")
-	fmt.Println(structstring.ConvertStructToString(route.RespType(), ssCfg))
+	fmt.Println()
+	fmt.Println("Example:")
+	fmt.Println("  " + example(route))
 
 	return nil
 }
@@ -0,0 +1,80 @@
+package apiexec_openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/openapi"
+	"github.com/anti-raid/evil-befall/pkg/constants"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type ApiExecOpenApiRoute struct {
+}
+
+func (r *ApiExecOpenApiRoute) Command() string {
+	return "apiexec.openapi"
+}
+
+func (r *ApiExecOpenApiRoute) Description() string {
+	return "Generates an OpenAPI 3.1 document from the testable API routes"
+}
+
+func (r *ApiExecOpenApiRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "file", Description: "File to write the document to. Printed if unset", Type: router.ArgTypeString},
+		{
+			Name:        "server",
+			Description: "Server URL to include in the document",
+			Type:        router.ArgTypeString,
+			DefaultFunc: func(state *state.State) string {
+				if state.StateFetchOptions.InstanceAPIUrl != "" {
+					return state.StateFetchOptions.InstanceAPIUrl
+				}
+
+				return constants.DefaultInstanceUrl
+			},
+			DefaultHelp: "[current instance url]",
+		},
+	}
+}
+
+func (r *ApiExecOpenApiRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *ApiExecOpenApiRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *ApiExecOpenApiRoute) Render(state *state.State, args map[string]string) error {
+	doc := openapi.Generate(api.GetTestableRouteCategories(), args["server"])
+
+	bytes, err := json.MarshalIndent(doc, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("error marshalling document: %w", err)
+	}
+
+	if args["file"] == "" {
+		fmt.Println(string(bytes))
+		return nil
+	}
+
+	err = os.WriteFile(args["file"], bytes, 0644)
+
+	if err != nil {
+		return fmt.Errorf("error writing document: %w", err)
+	}
+
+	fmt.Println("Wrote OpenAPI document to", args["file"])
+
+	return nil
+}
+
+func (r *ApiExecOpenApiRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return openapi.Generate(api.GetTestableRouteCategories(), args["server"]), nil
+}
@@ -6,6 +6,7 @@ import (
 	"slices"
 
 	"github.com/anti-raid/evil-befall/pkg/api/users"
+	"github.com/anti-raid/evil-befall/pkg/router"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/pkg/tui"
 	"github.com/rivo/tview"
@@ -24,10 +25,10 @@ func (r *ChooseGuildRoute) Description() string {
 	return "Choose the selected guild"
 }
 
-func (r *ChooseGuildRoute) Arguments() [][3]string {
-	return [][3]string{
-		{"guild_id", "The ID of the guild to choose. If unset, will show guild picker", "string"},
-		{"refresh", "Whether to refresh the guild list", "bool"},
+func (r *ChooseGuildRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "guild_id", Description: "The ID of the guild to choose. If unset, will show guild picker", Type: router.ArgTypeString},
+		{Name: "refresh", Description: "Whether to refresh the guild list", Type: router.ArgTypeBool, Default: "false"},
 	}
 }
 
@@ -51,13 +52,7 @@ func (r *ChooseGuildRoute) Render(state *state.State, args map[string]string) er
 		return state.SetSelectedGuild(guildID)
 	}
 
-	refresh := false
-
-	if refreshStr, ok := args["refresh"]; ok {
-		if refreshStr == "true" {
-			refresh = true
-		}
-	}
+	refresh := args["refresh"] == "true"
 
 	slog.Info("Fetching user guild list...", slog.Bool("refresh", refresh))
 
@@ -0,0 +1,49 @@
+// Package collection provides the collection.* routes to save, organize, run, import and export collections
+package collection
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+// Completes collection names and folders
+func completeCollectionNames(state *state.State, partial string) ([]string, error) {
+	store, err := collections.Load(collections.StorePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, c := range store.Collections {
+		if strings.HasPrefix(c.Name, partial) {
+			names = append(names, c.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// Loads the store and the named collection
+func loadCollection(name string) (*collections.Store, *collections.Collection, error) {
+	store, err := collections.Load(collections.StorePath)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := store.Get(name)
+
+	if c == nil {
+		return nil, nil, collectionNotFound(name)
+	}
+
+	return store, c, nil
+}
+
+func collectionNotFound(name string) error {
+	return fmt.Errorf("%w: %s", collections.ErrCollectionNotFound, name)
+}
@@ -0,0 +1,128 @@
+package collection
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type LsRoute struct {
+}
+
+func (r *LsRoute) Command() string {
+	return "collection.ls"
+}
+
+func (r *LsRoute) Description() string {
+	return "Lists collections by folder, or the variables and requests of a collection"
+}
+
+func (r *LsRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "name", Description: "A collection to show, or a folder to list", Type: router.ArgTypeString, Completion: completeCollectionNames},
+	}
+}
+
+func (r *LsRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *LsRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *LsRoute) Render(state *state.State, args map[string]string) error {
+	store, err := collections.Load(collections.StorePath)
+
+	if err != nil {
+		return err
+	}
+
+	if c := store.Get(args["name"]); c != nil {
+		printCollection(c)
+		return nil
+	}
+
+	matched := store.Match(args["name"])
+
+	if len(matched) == 0 {
+		if args["name"] != "" {
+			return collectionNotFound(args["name"])
+		}
+
+		fmt.Println("No collections saved yet, use collection.save to create one")
+		return nil
+	}
+
+	// Print a tree, with folders printed once before their collections
+	var printedFolders []string
+	for _, c := range matched {
+		parts := strings.Split(c.Name, "/")
+
+		for depth := 1; depth < len(parts); depth++ {
+			folder := strings.Join(parts[:depth], "/")
+
+			if !slices.Contains(printedFolders, folder) {
+				printedFolders = append(printedFolders, folder)
+				fmt.Printf("%s%s/\n", strings.Repeat("  ", depth-1), parts[depth-1])
+			}
+		}
+
+		fmt.Printf("%s%s (%d request(s))\n", strings.Repeat("  ", len(parts)-1), parts[len(parts)-1], len(c.Requests))
+	}
+
+	return nil
+}
+
+func printCollection(c *collections.Collection) {
+	fmt.Println("Collection:", c.Name)
+
+	if len(c.Variables) > 0 {
+		fmt.Println("Variables:")
+
+		for _, k := range sortedKeys(c.Variables) {
+			fmt.Printf("  %s=%s\n", k, c.Variables[k])
+		}
+	}
+
+	fmt.Println("Requests:")
+
+	for i, req := range c.Requests {
+		line := fmt.Sprintf("  %d. %s: %s", i+1, req.Label, req.Route)
+
+		for _, k := range sortedKeys(req.Args) {
+			line += fmt.Sprintf(" %s=%s", k, quoteIfNeeded(req.Args[k]))
+		}
+
+		if api.GetTestableRoute(req.Route) == nil {
+			line += " [missing route]"
+		}
+
+		fmt.Println(line)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"'") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
@@ -0,0 +1,73 @@
+package collection
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type RmRoute struct {
+}
+
+func (r *RmRoute) Command() string {
+	return "collection.rm"
+}
+
+func (r *RmRoute) Description() string {
+	return "Removes a collection or a single request of it"
+}
+
+func (r *RmRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "name", Description: "The collection", Type: router.ArgTypeString, Required: true, Completion: completeCollectionNames},
+		{Name: "request", Description: "The number of the request to remove (see collection.ls). If unset, removes the whole collection", Type: router.ArgTypeInt},
+	}
+}
+
+func (r *RmRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *RmRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *RmRoute) Render(state *state.State, args map[string]string) error {
+	store, c, err := loadCollection(args["name"])
+
+	if err != nil {
+		return err
+	}
+
+	if args["request"] == "" {
+		store.Remove(c.Name)
+
+		if err := store.Save(collections.StorePath); err != nil {
+			return err
+		}
+
+		fmt.Println("Removed collection", c.Name)
+		return nil
+	}
+
+	n, err := strconv.Atoi(args["request"])
+
+	if err != nil || n < 1 || n > len(c.Requests) {
+		return fmt.Errorf("%w: request must be between 1 and %d", router.ErrInvalidArgument, len(c.Requests))
+	}
+
+	label := c.Requests[n-1].Label
+	c.Requests = slices.Delete(c.Requests, n-1, n)
+
+	if err := store.Save(collections.StorePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed request #%d (%s) from %s\n", n, label, c.Name)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package collection
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type RunRoute struct {
+}
+
+func (r *RunRoute) Command() string {
+	return "collection.run"
+}
+
+func (r *RunRoute) Description() string {
+	return "Runs the requests of a collection in order"
+}
+
+func (r *RunRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "name", Description: "The collection to run", Type: router.ArgTypeString, Required: true, Completion: completeCollectionNames},
+		{Name: "continue_on_error", Description: "Keep running requests after one fails", Type: router.ArgTypeBool, Default: "false"},
+	}
+}
+
+// Any argument that is not declared overrides a collection variable for this run
+func (r *RunRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *RunRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *RunRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *RunRoute) Render(state *state.State, args map[string]string) error {
+	_, c, err := loadCollection(args["name"])
+
+	if err != nil {
+		return err
+	}
+
+	// Fail before running anything if a route has disappeared
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	vars := maps.Clone(c.Variables)
+
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	for k, v := range args {
+		if k == "name" || k == "continue_on_error" {
+			continue
+		}
+
+		vars[k] = v
+	}
+
+	continueOnError := args["continue_on_error"] == "true"
+
+	var failed int
+	for i, req := range c.Requests {
+		fmt.Println(ansi.Color(ansi.Cyan, fmt.Sprintf("==> [%d/%d] %s (%s)", i+1, len(c.Requests), req.Label, req.Route)))
+
+		execArgs, err := req.ExecArgs(vars)
+
+		if err == nil {
+			err = router.Goto("apiexec.exec", state, execArgs)
+		}
+
+		if err != nil {
+			failed++
+			fmt.Println(ansi.Color(ansi.Red, "Error: "+err.Error()))
+
+			if !continueOnError {
+				return fmt.Errorf("collection %s stopped at request #%d (%s): %w", c.Name, i+1, req.Label, err)
+			}
+		}
+
+		fmt.Println()
+	}
+
+	fmt.Printf("%d of %d request(s) succeeded\n", len(c.Requests)-failed, len(c.Requests))
+
+	if failed > 0 {
+		return fmt.Errorf("%d request(s) of collection %s failed", failed, c.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package collection
+
+import (
+	"fmt"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type SaveRoute struct {
+}
+
+func (r *SaveRoute) Command() string {
+	return "collection.save"
+}
+
+func (r *SaveRoute) Description() string {
+	return "Saves an apiexec.exec call to a collection, creating it if needed"
+}
+
+func (r *SaveRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "name", Description: "The collection to save to. Use / to place it in folders, e.g. audits/modules", Type: router.ArgTypeString, Required: true, Completion: completeCollectionNames},
+		{Name: "route", Description: "The ID of the route to save", Type: router.ArgTypeString, Required: true, Completion: api.CompleteTestableRouteIDs},
+		{Name: "label", Description: "A label for the request", Type: router.ArgTypeString},
+	}
+}
+
+// Any argument that is not declared is saved as an apiexec.exec argument. Use ${var:name} for collection variables
+func (r *SaveRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *SaveRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *SaveRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *SaveRoute) Render(state *state.State, args map[string]string) error {
+	if api.GetTestableRoute(args["route"]) == nil {
+		return fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, args["route"])
+	}
+
+	store, err := collections.Load(collections.StorePath)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := store.GetOrCreate(args["name"])
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", router.ErrInvalidArgument, err)
+	}
+
+	req := &collections.Request{
+		Label: args["label"],
+		Route: args["route"],
+		Args:  map[string]string{},
+	}
+
+	if req.Label == "" {
+		req.Label = req.Route
+	}
+
+	for k, v := range args {
+		if k == "name" || k == "route" || k == "label" {
+			continue
+		}
+
+		req.Args[k] = v
+	}
+
+	c.Requests = append(c.Requests, req)
+
+	if err := store.Save(collections.StorePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved %s as request #%d of %s\n", req.Label, len(c.Requests), c.Name)
+
+	return nil
+}
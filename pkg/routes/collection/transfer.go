@@ -0,0 +1,147 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type ExportRoute struct {
+}
+
+func (r *ExportRoute) Command() string {
+	return "collection.export"
+}
+
+func (r *ExportRoute) Description() string {
+	return "Exports collections to a single JSON file"
+}
+
+func (r *ExportRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "file", Description: "The file to write to, - for stdout", Type: router.ArgTypeString, Required: true},
+		{Name: "name", Description: "Only export this collection or folder", Type: router.ArgTypeString, Completion: completeCollectionNames},
+	}
+}
+
+func (r *ExportRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *ExportRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *ExportRoute) Render(state *state.State, args map[string]string) error {
+	store, err := collections.Load(collections.StorePath)
+
+	if err != nil {
+		return err
+	}
+
+	export := &collections.Store{
+		Version:     collections.Version,
+		Collections: store.Match(args["name"]),
+	}
+
+	if len(export.Collections) == 0 {
+		if args["name"] != "" {
+			return collectionNotFound(args["name"])
+		}
+
+		return fmt.Errorf("%w: nothing to export", collections.ErrCollectionNotFound)
+	}
+
+	if args["file"] == "-" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(export)
+	}
+
+	if err := export.Save(args["file"]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d collection(s) to %s\n", len(export.Collections), args["file"])
+
+	return nil
+}
+
+type ImportRoute struct {
+}
+
+func (r *ImportRoute) Command() string {
+	return "collection.import"
+}
+
+func (r *ImportRoute) Description() string {
+	return "Imports collections from a file created by collection.export"
+}
+
+func (r *ImportRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "file", Description: "The file to import, - for stdin", Type: router.ArgTypeString, Required: true},
+		{Name: "overwrite", Description: "Replace existing collections with the same name", Type: router.ArgTypeBool, Default: "false"},
+	}
+}
+
+func (r *ImportRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *ImportRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *ImportRoute) Render(state *state.State, args map[string]string) error {
+	var in io.Reader = os.Stdin
+
+	if args["file"] != "-" {
+		f, err := os.Open(args["file"])
+
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", args["file"], err)
+		}
+
+		defer f.Close()
+
+		in = f
+	}
+
+	imported, err := collections.Decode(in)
+
+	if err != nil {
+		return err
+	}
+
+	// Routes may have been removed since the export, this is only fatal when running the collection
+	for _, c := range imported.Collections {
+		if err := c.Validate(); err != nil {
+			slog.Warn("Imported collection references a missing route", slog.String("err", err.Error()))
+		}
+	}
+
+	store, err := collections.Load(collections.StorePath)
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.Merge(imported, args["overwrite"] == "true"); err != nil {
+		return err
+	}
+
+	if err := store.Save(collections.StorePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d collection(s)\n", len(imported.Collections))
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package collection
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/collections"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type VarsRoute struct {
+}
+
+func (r *VarsRoute) Command() string {
+	return "collection.vars"
+}
+
+func (r *VarsRoute) Description() string {
+	return "Shows or sets the variables of a collection"
+}
+
+func (r *VarsRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "name", Description: "The collection", Type: router.ArgTypeString, Required: true, Completion: completeCollectionNames},
+		{Name: "unset", Description: "Comma-separated variables to remove", Type: router.ArgTypeString},
+	}
+}
+
+// Any argument that is not declared sets a variable
+func (r *VarsRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *VarsRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *VarsRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *VarsRoute) Render(state *state.State, args map[string]string) error {
+	store, c, err := loadCollection(args["name"])
+
+	if err != nil {
+		return err
+	}
+
+	var changed bool
+
+	for k, v := range args {
+		if k == "name" || k == "unset" {
+			continue
+		}
+
+		if c.Variables == nil {
+			c.Variables = map[string]string{}
+		}
+
+		c.Variables[k] = v
+		changed = true
+	}
+
+	for _, k := range strings.Split(args["unset"], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			delete(c.Variables, k)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := store.Save(collections.StorePath); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range sortedKeys(c.Variables) {
+		fmt.Printf("%s=%s\n", k, c.Variables[k])
+	}
+
+	return nil
+}
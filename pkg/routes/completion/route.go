@@ -0,0 +1,145 @@
+package completion
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+// The getcompletion format the generated scripts call back into the binary with. It prints one completion per
+// line, each being the word to complete rather than the whole line
+const ShellFormat = "shell"
+
+var nonIdentRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Candidates ending in '=' are argument names and must not have a space appended. Candidates may be followed by
+// a tab and a description, which bash cannot show
+var scripts = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(`# bash completion for {{.Name}}
+#
+# Install with: source <({{.Name}} --command "completion shell=bash")
+_{{.Func}}_completion() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local args="${line#* }"
+    [[ "$line" != *" "* ]] && args=""
+    local cur="${line##* }"
+
+    local IFS=$'\n'
+    local candidates=($({{.Name}} getcompletion format={{.Format}} "line=${args:-@empty}" 2>/dev/null))
+
+    # bash splits words on '=' and ':', so strip everything up to the last such break in the current word
+    local prefix="" i ch
+    for (( i=${#cur}-1; i>=0; i-- )); do
+        ch="${cur:i:1}"
+        if [[ "$ch" == [=:] && "$COMP_WORDBREAKS" == *"$ch"* ]]; then
+            prefix="${cur:0:i+1}"
+            break
+        fi
+    done
+
+    COMPREPLY=()
+    local c
+    for c in "${candidates[@]}"; do
+        c="${c%%$'\t'*}"
+        [[ "$c" == "$cur"* ]] || continue
+        if [[ "$c" == *= ]]; then
+            COMPREPLY+=("${c#"$prefix"}")
+        else
+            COMPREPLY+=("${c#"$prefix"} ")
+        fi
+    done
+
+    compopt -o nospace 2>/dev/null
+}
+complete -F _{{.Func}}_completion {{.Name}}
+`)),
+	"zsh": template.Must(template.New("zsh").Parse(`#compdef {{.Name}}
+#
+# Install with: source <({{.Name}} --command "completion shell=zsh")
+_{{.Func}}() {
+    local -a candidates args values displays
+    local line="${(j: :)words[2,CURRENT]}"
+    candidates=("${(@f)$({{.Name}} getcompletion format={{.Format}} "line=${line:-@empty}" 2>/dev/null)}")
+
+    local c
+    for c in "${candidates[@]}"; do
+        [[ -z "$c" ]] && continue
+        if [[ "$c" == *= ]]; then
+            args+=("$c")
+        elif [[ "$c" == *$'\t'* ]]; then
+            values+=("${c%%$'\t'*}")
+            displays+=("${c%%$'\t'*}  -- ${c#*$'\t'}")
+        else
+            values+=("$c")
+            displays+=("$c")
+        fi
+    done
+
+    (( ${#args} )) && compadd -S '' -- "${args[@]}"
+    (( ${#values} )) && compadd -l -d displays -- "${values[@]}"
+}
+compdef _{{.Func}} {{.Name}}
+`)),
+	"fish": template.Must(template.New("fish").Parse(`# fish completion for {{.Name}}
+#
+# Install with: {{.Name}} --command "completion shell=fish" | source
+function __{{.Func}}_complete
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l cur (commandline -ct)
+    set -l line (string join ' ' -- $tokens $cur)
+    test -z "$line"; and set line @empty
+    {{.Name}} getcompletion format={{.Format}} "line=$line" 2>/dev/null
+end
+complete -c {{.Name}} -f -a '(__{{.Func}}_complete)'
+`)),
+}
+
+type CompletionRoute struct {
+}
+
+func (r *CompletionRoute) Command() string {
+	return "completion"
+}
+
+func (r *CompletionRoute) Description() string {
+	return "Generate a completion script for your system shell"
+}
+
+func (r *CompletionRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "shell", Description: "The shell to generate a completion script for", Type: router.ArgTypeString, Required: true, Enum: []string{"bash", "zsh", "fish"}},
+		{Name: "name", Description: "The name of the binary to complete", Type: router.ArgTypeString, DefaultFunc: binaryName, DefaultHelp: "[current binary name]"},
+	}
+}
+
+func (r *CompletionRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *CompletionRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *CompletionRoute) Render(state *state.State, args map[string]string) error {
+	tmpl, ok := scripts[args["shell"]]
+
+	if !ok {
+		return errors.New("unsupported shell")
+	}
+
+	return tmpl.Execute(os.Stdout, map[string]string{
+		"Name":   args["name"],
+		"Func":   nonIdentRegex.ReplaceAllString(args["name"], "_"),
+		"Format": ShellFormat,
+	})
+}
+
+func binaryName(state *state.State) string {
+	return filepath.Base(os.Args[0])
+}
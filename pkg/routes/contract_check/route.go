@@ -0,0 +1,138 @@
+package contract_check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/contract"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type ContractCheckRoute struct {
+}
+
+func (r *ContractCheckRoute) Command() string {
+	return "contract.check"
+}
+
+func (r *ContractCheckRoute) Description() string {
+	return "Checks API responses for unknown fields, missing fields and type mismatches against the Go types"
+}
+
+func (r *ContractCheckRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "route", Description: "The route to check. If unset, all GET routes are checked", Type: router.ArgTypeString, Completion: api.CompleteTestableRouteIDs},
+		{Name: "guildId", Description: "The guild to use for routes with a path:guildId field", Type: router.ArgTypeString, DefaultFunc: router.SelectedGuildDefault, DefaultHelp: "[selected guild id]"},
+	}
+}
+
+// Any argument that is not declared is a request field in KEY::TYPE=VALUE form, applied to all routes that have it
+func (r *ContractCheckRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *ContractCheckRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *ContractCheckRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *ContractCheckRoute) Render(state *state.State, args map[string]string) error {
+	reports, err := r.check(state, args)
+
+	if err != nil {
+		return err
+	}
+
+	var counts = map[contract.Status]int{}
+
+	for _, report := range reports {
+		report.Print(os.Stdout)
+		counts[report.Status]++
+	}
+
+	fmt.Printf("\n%d ok, %d drifted, %d errored, %d skipped\n", counts[contract.StatusOk], counts[contract.StatusDrift], counts[contract.StatusError], counts[contract.StatusSkipped])
+
+	if counts[contract.StatusDrift] > 0 {
+		return fmt.Errorf("%w: %d route(s) drifted", contract.ErrDrift, counts[contract.StatusDrift])
+	}
+
+	return nil
+}
+
+func (r *ContractCheckRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return r.check(state, args)
+}
+
+// Returns the routes to check. Only GET routes are checked unless a route is explicitly given as others may modify data
+func routesToCheck(args map[string]string) ([]api.TestableRoute, error) {
+	if id := args["route"]; id != "" {
+		route := api.GetTestableRoute(id)
+
+		if route == nil {
+			return nil, fmt.Errorf("%w: %s", api.ErrTestableRouteNotFound, id)
+		}
+
+		return []api.TestableRoute{route}, nil
+	}
+
+	var routes []api.TestableRoute
+
+	for _, route := range api.GetTestableRoutes() {
+		if strings.EqualFold(route.Meta().Method, "GET") {
+			routes = append(routes, route)
+		}
+	}
+
+	return routes, nil
+}
+
+func (r *ContractCheckRoute) check(state *state.State, args map[string]string) ([]*contract.Report, error) {
+	routes, err := routesToCheck(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := apiexec_exec.ParseRequestArgs(state, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delete(fields, "guildId")
+
+	var reports []*contract.Report
+
+	for _, route := range routes {
+		// Only pass on the fields the route has
+		routeArgs := map[string]any{}
+
+		for _, f := range api.RequestFields(reflect.TypeOf(route.ReqType())) {
+			if v, ok := fields[f.Key]; ok {
+				routeArgs[f.Key] = v
+			} else if f.Key == "path:guildId" && args["guildId"] != "" {
+				routeArgs[f.Key] = args["guildId"]
+			}
+		}
+
+		populated, err := route.PopulateWithArgs(routeArgs)
+
+		if err != nil {
+			reports = append(reports, &contract.Report{RouteID: route.ID(), Status: contract.StatusError, Reason: err.Error()})
+			continue
+		}
+
+		reports = append(reports, contract.CheckRoute(context.TODO(), state, populated))
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,54 @@
+package dryrun
+
+import (
+	"fmt"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type DryRunRoute struct {
+}
+
+func (r *DryRunRoute) Command() string {
+	return "dryrun"
+}
+
+func (r *DryRunRoute) Description() string {
+	return "Turns dry-run mode on or off for this session. In dry-run mode, requests that would modify data are shown instead of being sent"
+}
+
+func (r *DryRunRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "enabled", Description: "Whether dry-run mode should be on", Type: router.ArgTypeBool, DefaultFunc: toggled, DefaultHelp: "[toggle]"},
+	}
+}
+
+func toggled(state *state.State) string {
+	if state.StateFetchOptions.DryRun {
+		return "false"
+	}
+
+	return "true"
+}
+
+func (r *DryRunRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *DryRunRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *DryRunRoute) Render(state *state.State, args map[string]string) error {
+	state.StateFetchOptions.DryRun = args["enabled"] == "true"
+
+	if state.StateFetchOptions.DryRun {
+		fmt.Println(ansi.Color(ansi.Yellow, "Dry-run mode is on, requests that are not GET requests will be shown instead of sent"))
+	} else {
+		fmt.Println("Dry-run mode is off")
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package guild_info
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/types/discordgo"
+	"github.com/anti-raid/evil-befall/types/ext_types"
+)
+
+// A permission from the Discord permission bitfield
+type permission struct {
+	bit  uint64
+	name string
+}
+
+var (
+	permAdministrator      = permission{1 << 3, "Administrator"}
+	permViewChannel        = permission{1 << 10, "View Channel"}
+	permSendMessages       = permission{1 << 11, "Send Messages"}
+	permManageMessages     = permission{1 << 13, "Manage Messages"}
+	permEmbedLinks         = permission{1 << 14, "Embed Links"}
+	permReadMessageHistory = permission{1 << 16, "Read Message History"}
+)
+
+// The permissions the bot needs in channels messages are sent in. Other channels only need to be visible
+var messagePermissions = []permission{permViewChannel, permSendMessages, permEmbedLinks, permReadMessageHistory, permManageMessages}
+
+var channelTypeNames = map[discordgo.ChannelType]string{
+	discordgo.ChannelTypeGuildText:          "text",
+	discordgo.ChannelTypeDM:                 "dm",
+	discordgo.ChannelTypeGuildVoice:         "voice",
+	discordgo.ChannelTypeGroupDM:            "group dm",
+	discordgo.ChannelTypeGuildCategory:      "category",
+	discordgo.ChannelTypeGuildNews:          "announcement",
+	discordgo.ChannelTypeGuildStore:         "store",
+	discordgo.ChannelTypeGuildNewsThread:    "announcement thread",
+	discordgo.ChannelTypeGuildPublicThread:  "public thread",
+	discordgo.ChannelTypeGuildPrivateThread: "private thread",
+	discordgo.ChannelTypeGuildStageVoice:    "stage",
+	discordgo.ChannelTypeGuildDirectory:     "directory",
+	discordgo.ChannelTypeGuildForum:         "forum",
+	discordgo.ChannelTypeGuildMedia:         "media",
+}
+
+func channelTypeName(t discordgo.ChannelType) string {
+	if name, ok := channelTypeNames[t]; ok {
+		return name
+	}
+
+	return "unknown (" + strconv.Itoa(int(t)) + ")"
+}
+
+// Compares snowflakes numerically
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return cmp.Compare(len(a), len(b))
+	}
+
+	return strings.Compare(a, b)
+}
+
+// Sorts roles in hierarchy order, highest first. Discord ranks roles with the same position by ID, older first
+func sortRoles(roles []ext_types.SerenityRole) {
+	slices.SortStableFunc(roles, func(a, b ext_types.SerenityRole) int {
+		if a.Position != b.Position {
+			return cmp.Compare(b.Position, a.Position)
+		}
+
+		return compareIDs(a.ID, b.ID)
+	})
+}
+
+// A category and the channels in it, with threads following their parent channel
+type channelGroup struct {
+	// Nil for channels that are not in a category
+	Category *ext_types.GuildChannelWithPermissions
+
+	Channels []ext_types.GuildChannelWithPermissions
+}
+
+func compareChannels(a, b ext_types.GuildChannelWithPermissions) int {
+	if a.Channel.Position != b.Channel.Position {
+		return cmp.Compare(a.Channel.Position, b.Channel.Position)
+	}
+
+	return compareIDs(a.Channel.ID, b.Channel.ID)
+}
+
+// Groups channels by category in the order Discord shows them: channels without a category first, then each
+// category by position
+func groupChannels(channels []ext_types.GuildChannelWithPermissions) []channelGroup {
+	var categories []ext_types.GuildChannelWithPermissions
+	var threads = map[string][]ext_types.GuildChannelWithPermissions{}
+	var byParent = map[string][]ext_types.GuildChannelWithPermissions{}
+
+	for _, c := range channels {
+		if c.Channel == nil {
+			continue
+		}
+
+		switch {
+		case c.Channel.Type == discordgo.ChannelTypeGuildCategory:
+			categories = append(categories, c)
+		case c.Channel.IsThread():
+			threads[c.Channel.ParentID] = append(threads[c.Channel.ParentID], c)
+		default:
+			byParent[c.Channel.ParentID] = append(byParent[c.Channel.ParentID], c)
+		}
+	}
+
+	slices.SortStableFunc(categories, compareChannels)
+
+	group := func(category *ext_types.GuildChannelWithPermissions, parentID string) channelGroup {
+		g := channelGroup{Category: category}
+
+		members := byParent[parentID]
+		delete(byParent, parentID)
+
+		slices.SortStableFunc(members, compareChannels)
+
+		for _, c := range members {
+			g.Channels = append(g.Channels, c)
+
+			children := threads[c.Channel.ID]
+			delete(threads, c.Channel.ID)
+
+			slices.SortStableFunc(children, compareChannels)
+			g.Channels = append(g.Channels, children...)
+		}
+
+		return g
+	}
+
+	groups := []channelGroup{group(nil, "")}
+
+	for i := range categories {
+		groups = append(groups, group(&categories[i], categories[i].Channel.ID))
+	}
+
+	// Channels whose parent is not in the list (and threads whose channel is not) are shown without a category
+	for _, orphans := range []map[string][]ext_types.GuildChannelWithPermissions{byParent, threads} {
+		var cs []ext_types.GuildChannelWithPermissions
+
+		for _, c := range orphans {
+			cs = append(cs, c...)
+		}
+
+		slices.SortStableFunc(cs, compareChannels)
+		groups[0].Channels = append(groups[0].Channels, cs...)
+	}
+
+	if len(groups[0].Channels) == 0 {
+		groups = groups[1:]
+	}
+
+	return groups
+}
+
+// Returns the names of the permissions the bot needs in a channel but does not have
+func missingPermissions(c ext_types.GuildChannelWithPermissions) []string {
+	required := []permission{permViewChannel}
+
+	switch c.Channel.Type {
+	case discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildForum, discordgo.ChannelTypeGuildMedia:
+		required = messagePermissions
+	}
+
+	// Unparseable permissions are treated as none
+	bits, _ := strconv.ParseUint(string(c.Bot), 10, 64)
+
+	if bits&permAdministrator.bit != 0 {
+		return nil
+	}
+
+	var missing []string
+	for _, p := range required {
+		if bits&p.bit == 0 {
+			missing = append(missing, p.name)
+		}
+	}
+
+	return missing
+}
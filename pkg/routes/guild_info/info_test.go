@@ -0,0 +1,71 @@
+package guild_info
+
+import (
+	"testing"
+
+	"github.com/anti-raid/evil-befall/types/discordgo"
+	"github.com/anti-raid/evil-befall/types/ext_types"
+	"github.com/stretchr/testify/assert"
+)
+
+func channel(id string, t discordgo.ChannelType, position int, parentID string) ext_types.GuildChannelWithPermissions {
+	return ext_types.GuildChannelWithPermissions{Channel: &discordgo.Channel{ID: id, Name: id, Type: t, Position: position, ParentID: parentID}}
+}
+
+func TestSortRoles(t *testing.T) {
+	roles := []ext_types.SerenityRole{{ID: "1", Position: 0}, {ID: "100", Position: 2}, {ID: "20", Position: 2}, {ID: "3", Position: 5}}
+	sortRoles(roles)
+
+	var ids []string
+	for _, r := range roles {
+		ids = append(ids, r.ID)
+	}
+
+	assert.Equal(t, []string{"3", "20", "100", "1"}, ids)
+}
+
+func TestGroupChannels(t *testing.T) {
+	groups := groupChannels([]ext_types.GuildChannelWithPermissions{
+		channel("cat2", discordgo.ChannelTypeGuildCategory, 1, ""),
+		channel("cat1", discordgo.ChannelTypeGuildCategory, 0, ""),
+		channel("b", discordgo.ChannelTypeGuildText, 1, "cat1"),
+		channel("a", discordgo.ChannelTypeGuildText, 0, "cat1"),
+		channel("thread", discordgo.ChannelTypeGuildPublicThread, 0, "a"),
+		channel("c", discordgo.ChannelTypeGuildVoice, 0, "cat2"),
+		channel("top", discordgo.ChannelTypeGuildText, 0, ""),
+		channel("orphan", discordgo.ChannelTypeGuildText, 3, "missing"),
+	})
+
+	var got [][]string
+	for _, g := range groups {
+		names := []string{""}
+
+		if g.Category != nil {
+			names[0] = g.Category.Channel.ID
+		}
+
+		for _, c := range g.Channels {
+			names = append(names, c.Channel.ID)
+		}
+
+		got = append(got, names)
+	}
+
+	assert.Equal(t, [][]string{{"", "top", "orphan"}, {"cat1", "a", "thread", "b"}, {"cat2", "c"}}, got)
+}
+
+func TestMissingPermissions(t *testing.T) {
+	text := channel("a", discordgo.ChannelTypeGuildText, 0, "")
+	text.Bot = "3072" // View Channel and Send Messages
+	assert.Equal(t, []string{"Embed Links", "Read Message History", "Manage Messages"}, missingPermissions(text))
+
+	text.Bot = "8" // Administrator
+	assert.Empty(t, missingPermissions(text))
+
+	voice := channel("b", discordgo.ChannelTypeGuildVoice, 0, "")
+	voice.Bot = "1024"
+	assert.Empty(t, missingPermissions(voice))
+
+	voice.Bot = "0"
+	assert.Equal(t, []string{"View Channel"}, missingPermissions(voice))
+}
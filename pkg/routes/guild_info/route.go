@@ -0,0 +1,171 @@
+package guild_info
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/api/users"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/ext_types"
+)
+
+type GuildInfoRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *GuildInfoRoute) Command() string {
+	return "guild.info"
+}
+
+func (r *GuildInfoRoute) Description() string {
+	return "Shows the roles and channels of a guild and the channels the bot is missing permissions in"
+}
+
+func (r *GuildInfoRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "guildId", Description: "The guild id", Type: router.ArgTypeString, Required: true, DefaultFunc: router.SelectedGuildDefault, DefaultHelp: "[selected guild id]"},
+	}
+}
+
+func (r *GuildInfoRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *GuildInfoRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+func (r *GuildInfoRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return users.GetUserGuildBaseInfo(r.ctx, state, &users.GetUserGuildBaseInfoData{GuildID: args["guildId"]})
+}
+
+func (r *GuildInfoRoute) Render(state *state.State, args map[string]string) error {
+	guild, err := users.GetUserGuildBaseInfo(r.ctx, state, &users.GetUserGuildBaseInfoData{GuildID: args["guildId"]})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ansi.Color(ansi.Bold, guild.Name) + " (" + args["guildId"] + ")")
+	fmt.Println("Owner: " + guild.OwnerID)
+	fmt.Println()
+
+	if err := printRoles(guild); err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	if err := printChannels(guild); err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	printMissingPermissions(guild)
+
+	return nil
+}
+
+func printRoles(guild *types.UserGuildBaseData) error {
+	roles := slices.Clone(guild.Roles)
+	sortRoles(roles)
+
+	fmt.Printf("Roles (%d)\n", len(roles))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  POSITION\tNAME\tID\t")
+
+	for _, role := range roles {
+		var holders []string
+
+		if slices.Contains(guild.UserRoles, role.ID) {
+			holders = append(holders, "you")
+		}
+
+		if slices.Contains(guild.BotRoles, role.ID) {
+			holders = append(holders, "bot")
+		}
+
+		fmt.Fprintf(w, "  %d\t%s\t%s\t%s\n", role.Position, role.Name, role.ID, strings.Join(holders, ", "))
+	}
+
+	return w.Flush()
+}
+
+func printChannels(guild *types.UserGuildBaseData) error {
+	groups := groupChannels(guild.Channels)
+
+	total := 0
+	for _, g := range groups {
+		total += len(g.Channels)
+	}
+
+	fmt.Printf("Channels (%d)\n", total)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, g := range groups {
+		if g.Category == nil {
+			fmt.Fprintln(w, "  (no category)\t\t")
+		} else {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", g.Category.Channel.Name, channelTypeName(g.Category.Channel.Type), g.Category.Channel.ID)
+		}
+
+		for _, c := range g.Channels {
+			indent := "    "
+
+			if c.Channel.IsThread() {
+				indent += "  "
+			}
+
+			fmt.Fprintf(w, "%s%s\t%s\t%s\n", indent, c.Channel.Name, channelTypeName(c.Channel.Type), c.Channel.ID)
+		}
+	}
+
+	return w.Flush()
+}
+
+func printMissingPermissions(guild *types.UserGuildBaseData) {
+	var lines []string
+
+	for _, g := range groupChannels(guild.Channels) {
+		channels := g.Channels
+
+		if g.Category != nil {
+			channels = append([]ext_types.GuildChannelWithPermissions{*g.Category}, channels...)
+		}
+
+		for _, c := range channels {
+			if missing := missingPermissions(c); len(missing) > 0 {
+				lines = append(lines, fmt.Sprintf("  %s (%s): %s", c.Channel.Name, c.Channel.ID, strings.Join(missing, ", ")))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		fmt.Println(ansi.Color(ansi.Green, "The bot has the permissions it needs in every channel"))
+		return
+	}
+
+	fmt.Println(ansi.Color(ansi.Yellow, fmt.Sprintf("The bot is missing permissions in %d channel(s)", len(lines))))
+
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
@@ -2,12 +2,14 @@ package login
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/anti-raid/evil-befall/pkg/api/core"
 	"github.com/anti-raid/evil-befall/pkg/auth"
 	"github.com/anti-raid/evil-befall/pkg/constants"
+	"github.com/anti-raid/evil-befall/pkg/router"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/pkg/tui"
 	"github.com/pkg/browser"
@@ -27,8 +29,8 @@ func (r *LoginRoute) Description() string {
 	return "Log in to an Anti-Raid instance"
 }
 
-func (r *LoginRoute) Arguments() [][3]string {
-	return [][3]string{}
+func (r *LoginRoute) Arguments() []router.Argument {
+	return []router.Argument{}
 }
 
 func (r *LoginRoute) Setup(state *state.State) error {
@@ -47,6 +49,11 @@ func (r *LoginRoute) Destroy(state *state.State) error {
 }
 
 func (r *LoginRoute) Render(state *state.State, args map[string]string) error {
+	if state.StateFetchOptions.DryRun {
+		// Creating the session is a POST request which would not be sent
+		return errors.New("cannot log in while dry-run mode is on, turn it off with dryrun enabled=false")
+	}
+
 	var continueChan = make(chan bool)
 	var doneChan = make(chan struct{})
 
@@ -2,12 +2,14 @@ package publish
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/settings"
 	"github.com/anti-raid/evil-befall/pkg/state"
 	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
@@ -22,15 +24,15 @@ func (r *PublishRoute) Description() string {
 	return "Publish a template to a setting. Note that other settings may work but this is only intended for use in templates"
 }
 
-func (r *PublishRoute) Arguments() [][3]string {
-	return [][3]string{
-		{"guildId", "The guild id", "[selected guild id]"},
-		{"module", "The module", ""},
-		{"setting", "The setting ID"},
-		{"pkey", "The primary key to use for update", ""},
-		{"pkeyValue", "The primary key value to use for update", ""},
-		{"key", "The key to update"},
-		{"value", "The value to set"},
+func (r *PublishRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "guildId", Description: "The guild id", Type: router.ArgTypeString, Required: true, DefaultFunc: router.SelectedGuildDefault, DefaultHelp: "[selected guild id]"},
+		{Name: "module", Description: "The module", Type: router.ArgTypeString, Required: true},
+		{Name: "setting", Description: "The setting ID", Type: router.ArgTypeString, Required: true},
+		{Name: "pkey", Description: "The primary key to use for update", Type: router.ArgTypeString, Required: true},
+		{Name: "pkeyValue", Description: "The primary key value to use for update", Type: router.ArgTypeString, Required: true},
+		{Name: "key", Description: "The key to update", Type: router.ArgTypeString, Required: true},
+		{Name: "value", Description: "The value to set", Type: router.ArgTypeString, Required: true},
 	}
 }
 
@@ -43,68 +45,71 @@ func (r *PublishRoute) Destroy(state *state.State) error {
 }
 
 func (r *PublishRoute) Render(state *state.State, args map[string]string) error {
-	guildId := state.SelectedOptions.GuildID
+	// All arguments are required and so have been validated by the router
+	guildId := args["guildId"]
+	module := args["module"]
+	setting := args["setting"]
+	pkey := args["pkey"]
+	pvalue := args["pkeyValue"]
+	key := args["key"]
+	value := args["value"]
 
-	if v, ok := args["guildId"]; ok {
-		guildId = v
-	}
-
-	module, ok := args["module"]
+	fields := orderedmap.New[string, any]()
 
-	if !ok {
-		return errors.New("module is required")
-	}
+	fields.Set(key, value)
+	fields.Set(pkey, pvalue)
 
-	setting, ok := args["setting"]
+	opt, err := settings.FindConfigOption(context.Background(), state, module, setting)
 
-	if !ok {
-		return errors.New("setting is required")
+	if err != nil {
+		return err
 	}
 
-	pkey, ok := args["pkey"]
+	rows, err := settings.Execute(context.Background(), state, guildId, module, opt, silverpelt.Update, fields)
 
-	if !ok {
-		return errors.New("pkey is required")
+	if err != nil {
+		return err
 	}
 
-	pvalue, ok := args["pkeyValue"]
-
-	if !ok {
-		return errors.New("pkeyValue is required")
+	if state.StateFetchOptions.DryRun {
+		fmt.Println("Not published (dry run)")
+		return nil
 	}
 
-	key, ok := args["key"]
+	fmt.Println(rows)
 
-	if !ok {
-		return errors.New("key is required")
-	}
+	return nil
+}
 
-	value, ok := args["value"]
+// The arguments whose values are completed from the suggestions for the column named by another argument
+var suggestedColumns = map[string]string{
+	"pkeyValue": "pkey",
+	"value":     "key",
+}
 
-	if !ok {
-		return errors.New("value is required")
-	}
+func (r *PublishRoute) Completion(state *state.State, line string, args map[string]string) ([]string, error) {
+	name, partial, ok := router.TypingValue(line)
+	args = router.LineArgs(line)
 
-	fields := orderedmap.New[string, any]()
+	if column := args[suggestedColumns[name]]; ok && column != "" && args["module"] != "" && args["setting"] != "" {
+		guildId := args["guildId"]
 
-	fields.Set(key, value)
-	fields.Set(pkey, pvalue)
+		if guildId == "" {
+			guildId = router.SelectedGuildDefault(state)
+		}
 
-	resp, err := guilds.SettingsExecute(context.Background(), state, &guilds.SettingsExecuteData{
-		GuildID: guildId,
-		SettingsExecuteData: &types.SettingsExecute{
-			Operation: "Update",
-			Module:    module,
-			Setting:   setting,
-			Fields:    *fields,
-		},
-	})
+		candidates := settings.CompleteSuggestions(state, &guilds.SettingsGetSuggestionsData{
+			GuildID: guildId,
+			SettingsGetSuggestionsData: &types.SettingsGetSuggestions{
+				Operation: silverpelt.Update,
+				Module:    args["module"],
+				Setting:   args["setting"],
+				Column:    column,
+			},
+		}, partial)
 
-	if err != nil {
-		return err
+		return router.ValueCompletions(line, name, candidates), nil
 	}
 
-	fmt.Println(resp.Fields)
-
-	return nil
+	return router.CompleteArgs(r, state, line, args)
 }
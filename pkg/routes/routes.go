@@ -2,19 +2,58 @@ package routes
 
 import (
 	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_bench"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_diff"
 	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_exec"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_fanout"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_form"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_fuzz"
 	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_ls"
+	"github.com/anti-raid/evil-befall/pkg/routes/apiexec_openapi"
 	"github.com/anti-raid/evil-befall/pkg/routes/choose_guild"
+	"github.com/anti-raid/evil-befall/pkg/routes/collection"
+	"github.com/anti-raid/evil-befall/pkg/routes/completion"
+	"github.com/anti-raid/evil-befall/pkg/routes/contract_check"
+	"github.com/anti-raid/evil-befall/pkg/routes/dryrun"
+	"github.com/anti-raid/evil-befall/pkg/routes/guild_info"
 	"github.com/anti-raid/evil-befall/pkg/routes/login"
 	"github.com/anti-raid/evil-befall/pkg/routes/publish"
+	"github.com/anti-raid/evil-befall/pkg/routes/settings_form"
+	"github.com/anti-raid/evil-befall/pkg/routes/settings_view"
 	"github.com/anti-raid/evil-befall/pkg/routes/showstate"
+	"github.com/anti-raid/evil-befall/pkg/routes/view"
+	"github.com/anti-raid/evil-befall/pkg/routes/watch"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
 )
 
 func init() {
 	router.AddRoute(&apiexec_ls.ApiExecLsRoute{})
 	router.AddRoute(&apiexec_exec.ApiExecExecRoute{})
+	router.AddRoute(&apiexec_fanout.ApiExecFanoutRoute{})
+	router.AddRoute(&apiexec_openapi.ApiExecOpenApiRoute{})
 	router.AddRoute(&choose_guild.ChooseGuildRoute{})
+	router.AddRoute(&guild_info.GuildInfoRoute{})
 	router.AddRoute(&login.LoginRoute{})
 	router.AddRoute(&showstate.ShowStateRoute{})
 	router.AddRoute(&publish.PublishRoute{})
+	router.AddRoute(&settings_view.SettingsViewRoute{})
+	router.AddRoute(&settings_form.SettingsFormRoute{Operation: silverpelt.Create})
+	router.AddRoute(&settings_form.SettingsFormRoute{Operation: silverpelt.Update})
+	router.AddRoute(&settings_form.SettingsFormRoute{Operation: silverpelt.Delete})
+	router.AddRoute(&completion.CompletionRoute{})
+	router.AddRoute(&watch.WatchRoute{})
+	router.AddRoute(&contract_check.ContractCheckRoute{})
+	router.AddRoute(&collection.SaveRoute{})
+	router.AddRoute(&collection.RunRoute{})
+	router.AddRoute(&collection.LsRoute{})
+	router.AddRoute(&collection.VarsRoute{})
+	router.AddRoute(&collection.RmRoute{})
+	router.AddRoute(&collection.ExportRoute{})
+	router.AddRoute(&collection.ImportRoute{})
+	router.AddRoute(&apiexec_diff.ApiExecDiffRoute{})
+	router.AddRoute(&apiexec_bench.ApiExecBenchRoute{})
+	router.AddRoute(&apiexec_fuzz.ApiExecFuzzRoute{})
+	router.AddRoute(&apiexec_form.ApiExecFormRoute{})
+	router.AddRoute(&view.ViewRoute{})
+	router.AddRoute(&dryrun.DryRunRoute{})
 }
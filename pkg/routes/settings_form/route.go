@@ -0,0 +1,275 @@
+package settings_form
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/settings"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/pkg/tui"
+	"github.com/anti-raid/evil-befall/pkg/tui/settingsform"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	"github.com/rivo/tview"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const formHelp = "Tab/Shift+Tab move between fields, Esc cancels. Required fields are marked with *"
+
+// SettingsFormRoute creates, edits or deletes a row of a setting using a form, depending on its Operation
+type SettingsFormRoute struct {
+	Operation silverpelt.CanonicalOperationType
+
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *SettingsFormRoute) Command() string {
+	switch r.Operation {
+	case silverpelt.Create:
+		return "settings.create"
+	case silverpelt.Update:
+		return "settings.edit"
+	case silverpelt.Delete:
+		return "settings.delete"
+	}
+
+	return "settings." + string(r.Operation)
+}
+
+func (r *SettingsFormRoute) Description() string {
+	switch r.Operation {
+	case silverpelt.Create:
+		return "Creates a row of a setting using a form"
+	case silverpelt.Update:
+		return "Edits a row of a setting using a form filled in with its current values"
+	case silverpelt.Delete:
+		return "Shows a row of a setting and deletes it once confirmed"
+	}
+
+	return ""
+}
+
+func (r *SettingsFormRoute) Arguments() []router.Argument {
+	args := []router.Argument{
+		{Name: "guildId", Description: "The guild id", Type: router.ArgTypeString, Required: true, DefaultFunc: router.SelectedGuildDefault, DefaultHelp: "[selected guild id]"},
+		{Name: "module", Description: "The module", Type: router.ArgTypeString, Required: true},
+		{Name: "setting", Description: "The setting ID", Type: router.ArgTypeString, Required: true},
+	}
+
+	if r.Operation == silverpelt.Update || r.Operation == silverpelt.Delete {
+		args = append(args, router.Argument{Name: "pkey", Description: "The value of the primary key of the row", Type: router.ArgTypeString, Required: true})
+	}
+
+	return args
+}
+
+func (r *SettingsFormRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *SettingsFormRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+// Fetches the row being edited or deleted, identified by the value of its primary key
+func (r *SettingsFormRoute) findRow(state *state.State, opt *silverpelt.CanonicalConfigOption, args map[string]string) (*orderedmap.OrderedMap[string, any], error) {
+	column, ok := opt.Column(opt.PrimaryKey)
+
+	if !ok {
+		return nil, fmt.Errorf("the primary key %s of %s is not one of its columns", opt.PrimaryKey, opt.ID)
+	}
+
+	pkey, err := settings.ParseValue(column, args["pkey"])
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", router.ErrInvalidArgument, err)
+	}
+
+	filters := orderedmap.New[string, any]()
+	common := opt.FiltersFor(silverpelt.View)
+
+	for pair := common.Oldest(); pair != nil; pair = pair.Next() {
+		filters.Set(pair.Key, pair.Value)
+	}
+
+	filters.Set(opt.PrimaryKey, pkey)
+
+	rows, err := settings.Execute(r.ctx, state, args["guildId"], args["module"], opt, silverpelt.View, filters)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Filters are not guaranteed to be applied by the server, so the row is matched here too
+	for i := range rows {
+		if v, ok := rows[i].Get(opt.PrimaryKey); ok && settings.FormatValue(v) == settings.FormatValue(pkey) {
+			return &rows[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no row of %s has %s %s", opt.ID, opt.PrimaryKey, args["pkey"])
+}
+
+func (r *SettingsFormRoute) Render(state *state.State, args map[string]string) error {
+	opt, err := settings.FindConfigOption(r.ctx, state, args["module"], args["setting"])
+
+	if err != nil {
+		return err
+	}
+
+	if opt.Operations.Len() > 0 {
+		if _, ok := opt.Operations.Get(r.Operation); !ok {
+			return fmt.Errorf("%s does not support the %s operation", opt.ID, r.Operation)
+		}
+	}
+
+	var row *orderedmap.OrderedMap[string, any]
+
+	if r.Operation == silverpelt.Update || r.Operation == silverpelt.Delete {
+		row, err = r.findRow(state, opt, args)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	app := tui.NewTview(state)
+	form := settingsform.New(opt, r.Operation, row)
+	status := tview.NewTextView().SetDynamicColors(true).SetText(formHelp)
+
+	// The result of the operation, printed once the form is closed so it stays in the terminal
+	var mu sync.Mutex
+	var result []orderedmap.OrderedMap[string, any]
+	var done bool
+
+	// In dry-run mode the request is shown once the form is closed, as it would otherwise be printed over the form
+	var dryRunFields *orderedmap.OrderedMap[string, any]
+
+	var executing bool
+
+	form.Form.AddButton(buttonLabel(r.Operation), func() {
+		if executing {
+			return
+		}
+
+		fields, err := form.Value()
+
+		if err != nil {
+			status.SetText("[red]" + tview.Escape(err.Error()))
+			return
+		}
+
+		if state.StateFetchOptions.DryRun {
+			dryRunFields = fields
+			app.Stop()
+			return
+		}
+
+		executing = true
+		status.SetText("Sending...")
+
+		go func() {
+			rows, err := settings.Execute(r.ctx, state, args["guildId"], args["module"], opt, r.Operation, fields)
+
+			app.QueueUpdateDraw(func() {
+				executing = false
+
+				if err != nil {
+					status.SetText("[red]" + tview.Escape(err.Error()))
+					return
+				}
+
+				mu.Lock()
+				result = rows
+				done = true
+				mu.Unlock()
+
+				app.Stop()
+			})
+		}()
+	})
+
+	form.Form.AddButton("Cancel", app.Stop)
+	form.Form.SetCancelFunc(app.Stop)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form.Form, 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	go func() {
+		<-r.ctx.Done()
+		app.Stop()
+	}()
+
+	if err := app.SetRoot(layout, true).Run(); err != nil {
+		return err
+	}
+
+	if dryRunFields != nil {
+		if _, err := settings.Execute(r.ctx, state, args["guildId"], args["module"], opt, r.Operation, dryRunFields); err != nil {
+			return err
+		}
+
+		fmt.Println("Not sent (dry run)")
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !done {
+		fmt.Println("Cancelled")
+		return nil
+	}
+
+	fmt.Println(ansi.Color(ansi.Green, doneMessage(r.Operation)))
+
+	if len(result) > 0 {
+		b, err := json.MarshalIndent(result, "", "  ")
+
+		if err != nil {
+			return fmt.Errorf("failed to convert response to JSON: %w", err)
+		}
+
+		fmt.Println(string(b))
+	}
+
+	return nil
+}
+
+func buttonLabel(op silverpelt.CanonicalOperationType) string {
+	switch op {
+	case silverpelt.Create:
+		return "Create"
+	case silverpelt.Delete:
+		return "Delete"
+	}
+
+	return "Save"
+}
+
+func doneMessage(op silverpelt.CanonicalOperationType) string {
+	switch op {
+	case silverpelt.Create:
+		return "Created"
+	case silverpelt.Delete:
+		return "Deleted"
+	}
+
+	return "Updated"
+}
+
+func (r *SettingsFormRoute) Completion(state *state.State, line string, args map[string]string) ([]string, error) {
+	return settings.Complete(r, state, line, r.Operation)
+}
@@ -0,0 +1,240 @@
+package settings_view
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/settings"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Values longer than this are cut off in the table
+const maxCellLength = 40
+
+type SettingsViewRoute struct {
+	ctx           context.Context
+	ctxCancelFunc context.CancelFunc
+}
+
+func (r *SettingsViewRoute) Command() string {
+	return "settings.view"
+}
+
+func (r *SettingsViewRoute) Description() string {
+	return "Shows the rows of a setting as a table. Other arguments in COLUMN=VALUE form filter the rows"
+}
+
+func (r *SettingsViewRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "guildId", Description: "The guild id", Type: router.ArgTypeString, Required: true, DefaultFunc: router.SelectedGuildDefault, DefaultHelp: "[selected guild id]"},
+		{Name: "module", Description: "The module", Type: router.ArgTypeString, Required: true},
+		{Name: "setting", Description: "The setting ID", Type: router.ArgTypeString, Required: true},
+		{Name: "__common_filters", Description: "Apply the common filters of the setting. Filters given as arguments override them either way", Type: router.ArgTypeBool, Default: "true"},
+		{Name: "__secrets", Description: "Show the values of secret columns", Type: router.ArgTypeBool},
+	}
+}
+
+// Any argument that is not declared is a filter on a column
+func (r *SettingsViewRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *SettingsViewRoute) Setup(state *state.State) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	r.ctx = ctx
+	r.ctxCancelFunc = cancelFunc
+	return nil
+}
+
+func (r *SettingsViewRoute) Destroy(state *state.State) error {
+	if r.ctxCancelFunc != nil {
+		r.ctxCancelFunc()
+	}
+	return nil
+}
+
+// Fetches the config option of the setting and executes a View with its filters
+func (r *SettingsViewRoute) view(state *state.State, args map[string]string) (*silverpelt.CanonicalConfigOption, []orderedmap.OrderedMap[string, any], error) {
+	opt, err := settings.FindConfigOption(r.ctx, state, args["module"], args["setting"])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filters, err := buildFilters(opt, args)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := settings.Execute(r.ctx, state, args["guildId"], args["module"], opt, silverpelt.View, filters)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return opt, rows, nil
+}
+
+// Returns the fields of a View: the common filters of the setting unless disabled, overridden by the filters
+// given as arguments
+func buildFilters(opt *silverpelt.CanonicalConfigOption, args map[string]string) (*orderedmap.OrderedMap[string, any], error) {
+	filters := orderedmap.New[string, any]()
+
+	if args["__common_filters"] == "true" {
+		common := opt.FiltersFor(silverpelt.View)
+
+		for pair := common.Oldest(); pair != nil; pair = pair.Next() {
+			filters.Set(pair.Key, pair.Value)
+		}
+	}
+
+	var keys []string
+	for k := range args {
+		if !isDeclared(k) && !strings.HasPrefix(k, "__") {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		column, ok := opt.Column(k)
+
+		if !ok {
+			var ids []string
+			for _, c := range opt.Columns {
+				ids = append(ids, c.ID)
+			}
+
+			return nil, fmt.Errorf("%w: %s is not a column of %s, expected one of %s", router.ErrInvalidArgument, k, opt.ID, strings.Join(ids, ", "))
+		}
+
+		v, err := settings.ParseValue(column, args[k])
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", router.ErrInvalidArgument, err)
+		}
+
+		filters.Set(k, v)
+	}
+
+	return filters, nil
+}
+
+func isDeclared(name string) bool {
+	return slices.ContainsFunc((&SettingsViewRoute{}).Arguments(), func(a router.Argument) bool { return a.Name == name })
+}
+
+func (r *SettingsViewRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	_, rows, err := r.view(state, args)
+	return rows, err
+}
+
+func (r *SettingsViewRoute) Render(state *state.State, args map[string]string) error {
+	opt, rows, err := r.view(state, args)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ansi.Color(ansi.Bold, opt.Name) + " (" + opt.ID + ")")
+
+	if opt.Description != "" {
+		fmt.Println(opt.Description)
+	}
+
+	fmt.Println()
+
+	if len(rows) == 0 {
+		fmt.Println("No rows")
+		return nil
+	}
+
+	if err := printTable(opt, rows, args["__secrets"] == "true"); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("%d row(s)\n", len(rows))
+
+	// The server returns at most MaxReturn rows and has no way to ask for the next ones
+	if opt.MaxReturn > 0 && len(rows) >= opt.MaxReturn {
+		fmt.Println(ansi.Color(ansi.Yellow, fmt.Sprintf("At most %d rows are returned, so there may be more. Filter by a column to narrow them down", opt.MaxReturn)))
+	}
+
+	return nil
+}
+
+func printTable(opt *silverpelt.CanonicalConfigOption, rows []orderedmap.OrderedMap[string, any], secrets bool) error {
+	var columns []silverpelt.CanonicalColumn
+
+	for _, c := range opt.Columns {
+		if !c.IsIgnoredFor(silverpelt.View) {
+			columns = append(columns, c)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	var header []string
+
+	if opt.TitleTemplate != "" {
+		header = append(header, "TITLE")
+	}
+
+	for _, c := range columns {
+		header = append(header, strings.ToUpper(c.Name))
+	}
+
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for i := range rows {
+		var cells []string
+
+		if opt.TitleTemplate != "" {
+			cells = append(cells, cell(settings.Title(opt.TitleTemplate, &rows[i])))
+		}
+
+		for _, c := range columns {
+			v, ok := rows[i].Get(c.ID)
+
+			switch {
+			case !ok:
+				cells = append(cells, "-")
+			case c.Secret && !secrets && v != nil:
+				cells = append(cells, "********")
+			default:
+				cells = append(cells, cell(settings.FormatValue(v)))
+			}
+		}
+
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// Puts a value on one line and cuts it off at maxCellLength
+func cell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	if r := []rune(s); len(r) > maxCellLength {
+		return string(r[:maxCellLength-3]) + "..."
+	}
+
+	return s
+}
+
+func (r *SettingsViewRoute) Completion(state *state.State, line string, args map[string]string) ([]string, error) {
+	return settings.Complete(r, state, line, silverpelt.View)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/anti-raid/evil-befall/pkg/router"
 	"github.com/anti-raid/evil-befall/pkg/state"
 )
 
@@ -18,8 +19,8 @@ func (r *ShowStateRoute) Description() string {
 	return "Prints out the current state"
 }
 
-func (r *ShowStateRoute) Arguments() [][3]string {
-	return [][3]string{}
+func (r *ShowStateRoute) Arguments() []router.Argument {
+	return []router.Argument{}
 }
 
 func (r *ShowStateRoute) Setup(state *state.State) error {
@@ -41,3 +42,7 @@ func (r *ShowStateRoute) Render(state *state.State, args map[string]string) erro
 
 	return nil
 }
+
+func (r *ShowStateRoute) RenderData(state *state.State, args map[string]string) (any, error) {
+	return state, nil
+}
@@ -0,0 +1,53 @@
+package view
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/anti-raid/evil-befall/pkg/jsontree"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/pkg/tui/jsonview"
+)
+
+type ViewRoute struct {
+}
+
+func (r *ViewRoute) Command() string {
+	return "view"
+}
+
+func (r *ViewRoute) Description() string {
+	return "Open a JSON file in a collapsible tree viewer"
+}
+
+func (r *ViewRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "file", Description: "The JSON file to view, e.g. one written by apiexec.exec __file", Type: router.ArgTypeString, Required: true},
+	}
+}
+
+func (r *ViewRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *ViewRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *ViewRoute) Render(state *state.State, args map[string]string) error {
+	b, err := os.ReadFile(args["file"])
+
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args["file"], err)
+	}
+
+	tree, err := jsontree.Parse(b)
+
+	if err != nil {
+		return fmt.Errorf("failed to parse %s as JSON: %w", args["file"], err)
+	}
+
+	return jsonview.Run(state, filepath.Base(args["file"]), tree)
+}
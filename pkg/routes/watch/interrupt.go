@@ -0,0 +1,66 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package watch
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// The byte a terminal sends for Ctrl+C when it does not turn it into an interrupt
+const ctrlC = 0x03
+
+// Returns a context cancelled when Ctrl+C is pressed. While listening, the terminal does not turn Ctrl+C into an
+// interrupt (which makes the shell exit) but passes it on as input. The returned function must be called to
+// restore the terminal
+//
+// If stdin is not a terminal, Ctrl+C is left alone and the context is only cancelled by the returned function
+func notifyInterrupt() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fd := int(os.Stdin.Fd())
+
+	orig, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+
+	if err != nil {
+		return ctx, cancel
+	}
+
+	raw := *orig
+	raw.Lflag &^= unix.ISIG | unix.ICANON | unix.ECHO
+	// Time out reads after 100ms so the reader notices when it is no longer needed
+	raw.Cc[unix.VMIN] = 0
+	raw.Cc[unix.VTIME] = 1
+
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return ctx, cancel
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		buf := make([]byte, 1)
+		for ctx.Err() == nil {
+			n, err := unix.Read(fd, buf)
+
+			if err != nil && err != unix.EINTR && err != unix.EAGAIN {
+				return
+			}
+
+			if n == 1 && buf[0] == ctrlC {
+				cancel()
+			}
+		}
+	}()
+
+	return ctx, func() {
+		cancel()
+		// Wait for the reader so it cannot take input meant for the prompt
+		<-done
+		unix.IoctlSetTermios(fd, ioctlSetTermios, orig)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package watch
+
+import "context"
+
+// Ctrl+C cannot be intercepted here, so it exits the shell as usual and the context is only cancelled by the
+// returned function
+func notifyInterrupt() (context.Context, context.CancelFunc) {
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,198 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/anti-raid/evil-befall/pkg/jsonutil"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+)
+
+type WatchRoute struct {
+}
+
+func (r *WatchRoute) Command() string {
+	return "watch"
+}
+
+func (r *WatchRoute) Description() string {
+	return "Re-run a command on an interval, highlighting what changed. Arguments to the command must be given as key=value"
+}
+
+func (r *WatchRoute) Arguments() []router.Argument {
+	return []router.Argument{
+		{Name: "interval", Description: "How often to re-run the command", Type: router.ArgTypeDuration, Required: true},
+		{Name: "command", Description: "The command to run", Type: router.ArgTypeString, Required: true, Completion: completeCommand},
+		{Name: "watch.until.path", Description: "Stop once the value at this JSON path (e.g. state or statuses.0.level) equals watch.until.value", Type: router.ArgTypeString},
+		{Name: "watch.until.value", Description: "The value watch.until.path must equal to stop", Type: router.ArgTypeString},
+		{Name: "watch.max", Description: "Stop after this many runs. 0 means no limit", Type: router.ArgTypeInt, Default: "0"},
+	}
+}
+
+// All other arguments are passed to the watched command
+func (r *WatchRoute) AllowsUnknownArgs() bool {
+	return true
+}
+
+func (r *WatchRoute) Setup(state *state.State) error {
+	return nil
+}
+
+func (r *WatchRoute) Destroy(state *state.State) error {
+	return nil
+}
+
+func (r *WatchRoute) Render(state *state.State, args map[string]string) error {
+	interval, err := time.ParseDuration(args["interval"])
+
+	if err != nil {
+		return err
+	}
+
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	command := args["command"]
+
+	if command == r.Command() {
+		return errors.New("cannot watch the watch command")
+	}
+
+	if router.GetRoute(command) == nil {
+		return fmt.Errorf("%w: %s", router.ErrRouteNotFound, command)
+	}
+
+	untilPath, hasUntil := args["watch.until.path"]
+	untilValue := args["watch.until.value"]
+
+	if _, ok := args["watch.until.value"]; ok && !hasUntil {
+		return errors.New("watch.until.value requires watch.until.path")
+	}
+
+	maxRuns, _ := strconv.Atoi(args["watch.max"])
+
+	var targetArgs = map[string]string{}
+	for k, v := range args {
+		if k == "interval" || k == "command" || strings.HasPrefix(k, "watch.") {
+			continue
+		}
+
+		targetArgs[k] = v
+	}
+
+	// The shell exits on interrupt, so take over Ctrl+C while watching
+	ctx, stop := notifyInterrupt()
+	defer stop()
+
+	var prev []string
+
+	for run := 1; ; run++ {
+		fmt.Println(ansi.Color(ansi.Bold, fmt.Sprintf("Every %s: %s (run %d at %s, Ctrl+C to stop)", interval, command, run, time.Now().Format(time.TimeOnly))))
+
+		result, err := router.GotoData(command, state, targetArgs)
+
+		if err != nil {
+			fmt.Println(ansi.Color(ansi.Red, "Error: "+err.Error()))
+		} else {
+			normalized, lines := normalize(result)
+
+			printDiff(os.Stdout, prev, lines)
+			prev = lines
+
+			if hasUntil {
+				if v, ok := jsonutil.Lookup(normalized, untilPath); ok && jsonutil.ScalarString(v) == untilValue {
+					fmt.Println(ansi.Color(ansi.Green, fmt.Sprintf("Condition met: %s == %s", untilPath, untilValue)))
+					return nil
+				}
+			}
+		}
+
+		if maxRuns > 0 && run >= maxRuns {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped watching")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Normalizes a result to JSON if possible, returning the normalized value and the lines to diff
+//
+// Captured output that is not JSON is diffed as plain text
+func normalize(result any) (any, []string) {
+	if s, ok := result.(string); ok {
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s)), &v); err != nil {
+			return nil, strings.Split(strings.TrimRight(s, "\n"), "\n")
+		}
+
+		result = v
+	}
+
+	normalized, err := jsonutil.Normalize(result)
+
+	if err != nil {
+		return nil, []string{fmt.Sprint(result)}
+	}
+
+	indented, err := jsonutil.Indent(normalized)
+
+	if err != nil {
+		return normalized, []string{fmt.Sprint(result)}
+	}
+
+	return normalized, strings.Split(indented, "\n")
+}
+
+// Prints the lines, highlighting those added and removed since the previous run if there was one
+func printDiff(w io.Writer, prev, lines []string) {
+	if prev == nil {
+		fmt.Fprintln(w, strings.Join(lines, "\n"))
+		return
+	}
+
+	ops := jsonutil.DiffLines(prev, lines)
+
+	var changed bool
+	for _, op := range ops {
+		switch op.Kind {
+		case jsonutil.LineSame:
+			fmt.Fprintln(w, "  "+op.Line)
+		case jsonutil.LineAdded:
+			changed = true
+			fmt.Fprintln(w, ansi.Color(ansi.Green, "+ "+op.Line))
+		case jsonutil.LineRemoved:
+			changed = true
+			fmt.Fprintln(w, ansi.Color(ansi.Red, "- "+op.Line))
+		}
+	}
+
+	if !changed {
+		fmt.Fprintln(w, ansi.Color(ansi.Cyan, "(no changes since last run)"))
+	}
+}
+
+func completeCommand(state *state.State, partial string) ([]string, error) {
+	var commands []string
+
+	for _, r := range router.Routes() {
+		if r.Command() != "watch" {
+			commands = append(commands, r.Command())
+		}
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	// Captured JSON output and values are normalized the same way, so runs can be compared whatever the source
+	v, lines := normalize(`{"state": "running", "count": 2}` + "\n")
+	assert.Equal(t, []string{"{", `  "count": 2,`, `  "state": "running"`, "}"}, lines)
+	assert.Equal(t, map[string]any{"count": json.Number("2"), "state": "running"}, v)
+
+	_, fromValue := normalize(struct {
+		State string `json:"state"`
+		Count int    `json:"count"`
+	}{State: "running", Count: 2})
+	assert.Equal(t, lines, fromValue)
+
+	// Other output is compared as plain text
+	v, lines = normalize("line one\nline two\n")
+	assert.Nil(t, v)
+	assert.Equal(t, []string{"line one", "line two"}, lines)
+}
+
+func TestPrintDiff(t *testing.T) {
+	var buf bytes.Buffer
+
+	printDiff(&buf, nil, []string{"a", "b"})
+	assert.Equal(t, "a\nb\n", buf.String())
+
+	buf.Reset()
+	printDiff(&buf, []string{"a", "b"}, []string{"a", "c"})
+	assert.Equal(t, "  a\n"+ansi.Color(ansi.Red, "- b")+"\n"+ansi.Color(ansi.Green, "+ c")+"\n", buf.String())
+
+	buf.Reset()
+	printDiff(&buf, []string{"a"}, []string{"a"})
+	assert.Equal(t, "  a\n"+ansi.Color(ansi.Cyan, "(no changes since last run)")+"\n", buf.String())
+}
+
+func TestNotifyInterrupt(t *testing.T) {
+	// Stopping cancels the context whether or not stdin is a terminal
+	ctx, stop := notifyInterrupt()
+	assert.NoError(t, ctx.Err())
+
+	stop()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,171 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api/core"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+)
+
+// How long fetching modules or suggestions for a completion may take
+var CompletionTimeout = 3 * time.Second
+
+// Complete completes the module and setting arguments of settings commands and the values of their columns,
+// falling back to the argument spec of the route for everything else
+//
+// Column values are completed from the suggestions the server has for them for the given operation
+func Complete(r router.Route, state *state.State, line string, op silverpelt.CanonicalOperationType) ([]string, error) {
+	name, partial, ok := router.TypingValue(line)
+	args := router.LineArgs(line)
+
+	if !ok {
+		return router.CompleteArgs(r, state, line, args)
+	}
+
+	switch name {
+	case "module":
+		return completeFromModules(state, line, name, partial, func(m *silverpelt.CanonicalModule) []string {
+			return []string{router.Describe(m.ID, m.Name)}
+		})
+	case "setting":
+		return completeFromModules(state, line, name, partial, func(m *silverpelt.CanonicalModule) []string {
+			if m.ID != args["module"] {
+				return nil
+			}
+
+			var c []string
+			for _, opt := range m.ConfigOptions {
+				c = append(c, router.Describe(opt.ID, opt.Name))
+			}
+
+			return c
+		})
+	}
+
+	declared := slices.ContainsFunc(r.Arguments(), func(a router.Argument) bool { return a.Name == name })
+
+	if declared || strings.HasPrefix(name, "__") || args["module"] == "" || args["setting"] == "" {
+		return router.CompleteArgs(r, state, line, args)
+	}
+
+	guildId := args["guildId"]
+
+	if guildId == "" {
+		guildId = router.SelectedGuildDefault(state)
+	}
+
+	candidates := CompleteSuggestions(state, &guilds.SettingsGetSuggestionsData{
+		GuildID: guildId,
+		SettingsGetSuggestionsData: &types.SettingsGetSuggestions{
+			Operation: op,
+			Module:    args["module"],
+			Setting:   args["setting"],
+			Column:    name,
+		},
+	}, partial)
+
+	return router.ValueCompletions(line, name, candidates), nil
+}
+
+func completeFromModules(state *state.State, line, name, partial string, candidates func(m *silverpelt.CanonicalModule) []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), CompletionTimeout)
+	defer cancel()
+
+	modules, err := core.GetModules(ctx, state)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var c []string
+	for _, m := range *modules {
+		for _, candidate := range candidates(m) {
+			if text, _ := router.SplitDescription(candidate); strings.HasPrefix(strings.ToLower(text), strings.ToLower(partial)) {
+				c = append(c, candidate)
+			}
+		}
+	}
+
+	return router.ValueCompletions(line, name, c), nil
+}
+
+// CompleteSuggestions returns completion candidates for the value of a setting column from the suggestions the
+// server has for it. Candidates insert the ID of a suggestion and are described by its value
+//
+// Suggestions are filtered by the partial value typed so far, matching the start of the ID or anywhere in the value.
+// Suggestions are only a convenience, so there are no candidates if they cannot be fetched
+func CompleteSuggestions(state *state.State, data *guilds.SettingsGetSuggestionsData, partial string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), CompletionTimeout)
+	defer cancel()
+
+	// Fetching suggestions does not modify anything, so it is done in dry-run mode too
+	resp, err := guilds.SettingsGetSuggestions(fetch.WithReadOnly(ctx), state, data)
+
+	if err != nil {
+		slog.Debug("Failed to fetch setting suggestions", slog.String("column", data.SettingsGetSuggestionsData.Column), slog.String("err", err.Error()))
+		return nil
+	}
+
+	return suggestionCandidates(resp.Suggestions, partial)
+}
+
+func suggestionCandidates(suggestions []types.SettingsGetSuggestionSuggestion, partial string) []string {
+	partial = strings.ToLower(partial)
+
+	var ids []string
+	var candidates []string
+	for _, s := range suggestions {
+		id, value := suggestionString(s.ID), suggestionString(s.Value)
+
+		if id == "" || slices.Contains(ids, id) {
+			continue
+		}
+
+		if !strings.HasPrefix(strings.ToLower(id), partial) && !strings.Contains(strings.ToLower(value), partial) {
+			continue
+		}
+
+		ids = append(ids, id)
+
+		if value == id {
+			value = ""
+		}
+
+		candidates = append(candidates, router.Describe(id, value))
+	}
+
+	return candidates
+}
+
+// Returns the text of a suggestion ID or value. Strings are used as is and other values as JSON
+func suggestionString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		// Avoid exponents for large IDs
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
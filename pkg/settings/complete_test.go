@@ -0,0 +1,37 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestionCandidates(t *testing.T) {
+	suggestions := []types.SettingsGetSuggestionSuggestion{
+		{ID: "111", Value: "general"},
+		{ID: "222", Value: "Announcements"},
+		{ID: float64(1234567890123456), Value: "rules"},
+		{ID: "111", Value: "duplicate"},
+		{ID: "same", Value: "same"},
+	}
+
+	assert.Equal(t, []string{"111\tgeneral", "222\tAnnouncements", "1234567890123456\trules", "same"}, suggestionCandidates(suggestions, ""))
+
+	// The partial value matches the start of IDs or anywhere in values, ignoring case
+	assert.Equal(t, []string{"222\tAnnouncements"}, suggestionCandidates(suggestions, "NOUNCE"))
+	assert.Equal(t, []string{"1234567890123456\trules"}, suggestionCandidates(suggestions, "1234"))
+	assert.Empty(t, suggestionCandidates(suggestions, "11x"))
+}
+
+func TestCompleteSuggestionsFailure(t *testing.T) {
+	// Without a session suggestions cannot be fetched, which only means there are no candidates
+	candidates := CompleteSuggestions(&state.State{}, &guilds.SettingsGetSuggestionsData{
+		GuildID:                    "1",
+		SettingsGetSuggestionsData: &types.SettingsGetSuggestions{Module: "logging", Setting: "sinks", Column: "channel"},
+	}, "")
+
+	assert.Empty(t, candidates)
+}
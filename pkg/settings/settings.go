@@ -0,0 +1,223 @@
+// Package settings has helpers for working with the settings of a module using their CanonicalConfigOption
+package settings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api/core"
+	"github.com/anti-raid/evil-befall/pkg/api/guilds"
+	"github.com/anti-raid/evil-befall/pkg/fetch"
+	"github.com/anti-raid/evil-befall/pkg/router"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/types"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// FindConfigOption returns the config option of a setting from the modules of the instance
+func FindConfigOption(ctx context.Context, state *state.State, module, setting string) (*silverpelt.CanonicalConfigOption, error) {
+	modules, err := core.GetModules(ctx, state)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return silverpelt.FindConfigOption(*modules, module, setting)
+}
+
+// ParseValue converts a value typed on the command line or in a form into the JSON value for a column
+//
+// Arrays are given as JSON arrays, and null clears nullable columns. Intervals are sent as a number of seconds
+func ParseValue(column *silverpelt.CanonicalColumn, raw string) (any, error) {
+	if raw == "null" && column.Nullable {
+		return nil, nil
+	}
+
+	inner, array := column.ColumnType.Inner()
+
+	if !array {
+		v, err := parseScalar(inner, raw)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column.ID, err)
+		}
+
+		return v, nil
+	}
+
+	var items []json.RawMessage
+
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, fmt.Errorf("%s: must be a JSON array: %w", column.ID, err)
+	}
+
+	var values = make([]any, 0, len(items))
+
+	for _, item := range items {
+		// Strings are unquoted so they can be parsed as other types
+		var s string
+		if err := json.Unmarshal(item, &s); err != nil {
+			s = string(item)
+		}
+
+		v, err := parseScalar(inner, s)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column.ID, err)
+		}
+
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+func parseScalar(t silverpelt.CanonicalInnerColumnType, raw string) (any, error) {
+	switch {
+	case t.Uuid != nil:
+		if !silverpelt.IsUuid(strings.TrimSpace(raw)) {
+			return nil, fmt.Errorf("%q is not a UUID", raw)
+		}
+
+		return strings.ToLower(strings.TrimSpace(raw)), nil
+	case t.TimestampTz != nil:
+		if !silverpelt.IsTimestamp(strings.TrimSpace(raw), true) {
+			return nil, fmt.Errorf("%q is not an RFC 3339 time, e.g. 2024-01-02T15:04:05Z", raw)
+		}
+
+		return strings.TrimSpace(raw), nil
+	case t.Timestamp != nil:
+		if !silverpelt.IsTimestamp(strings.TrimSpace(raw), false) {
+			return nil, fmt.Errorf("%q is not a time, e.g. 2024-01-02T15:04:05", raw)
+		}
+
+		return strings.TrimSpace(raw), nil
+	case t.Interval != nil:
+		// Intervals are a number of seconds, but may be typed as durations such as 1h30m
+		raw = strings.TrimSpace(raw)
+
+		if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return secs, nil
+		}
+
+		d, err := time.ParseDuration(raw)
+
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number of seconds or a duration such as 1h30m", raw)
+		}
+
+		return int64(d / time.Second), nil
+	case t.Integer != nil || t.BitFlag != nil:
+		i, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+
+		return i, nil
+	case t.Float != nil:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+
+		return f, nil
+	case t.Boolean != nil:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+
+		return b, nil
+	case t.Json != nil:
+		var v any
+
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.UseNumber()
+
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		return v, nil
+	}
+
+	return raw, nil
+}
+
+// Execute executes an operation on a setting of a module of a guild, returning the rows of the response
+//
+// The fields are validated against the config option first, so invalid fields are rejected without a request.
+// Views do not modify data, so are sent even in dry-run mode
+func Execute(ctx context.Context, state *state.State, guildId, module string, opt *silverpelt.CanonicalConfigOption, op silverpelt.CanonicalOperationType, fields *orderedmap.OrderedMap[string, any]) ([]orderedmap.OrderedMap[string, any], error) {
+	if se := opt.ValidateFields(op, fields); se != nil {
+		return nil, fmt.Errorf("%w: %s", router.ErrInvalidArgument, fetch.NewSettingsErrorFormatter(*se).ToMarkdown())
+	}
+
+	if op == silverpelt.View {
+		ctx = fetch.WithReadOnly(ctx)
+	}
+
+	// Already validated against opt
+	resp, err := guilds.SettingsExecute(guilds.WithoutValidation(ctx), state, &guilds.SettingsExecuteData{
+		GuildID: guildId,
+		SettingsExecuteData: &types.SettingsExecute{
+			Operation: op,
+			Module:    module,
+			Setting:   opt.ID,
+			Fields:    *fields,
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Fields, nil
+}
+
+// FormatValue returns the text of a value in a row. Strings are shown as is and other values as JSON
+func FormatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+var titlePlaceholder = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+// Title renders the title of a row from the TitleTemplate of its setting, replacing {column} placeholders with the
+// values of the row. Placeholders of columns the row does not have are left as is
+func Title(template string, row *orderedmap.OrderedMap[string, any]) string {
+	return titlePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		v, ok := row.Get(placeholder[1 : len(placeholder)-1])
+
+		if !ok {
+			return placeholder
+		}
+
+		return FormatValue(v)
+	})
+}
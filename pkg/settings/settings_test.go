@@ -0,0 +1,81 @@
+package settings
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func column(t *testing.T, id, columnType string, nullable bool) *silverpelt.CanonicalColumn {
+	var c silverpelt.CanonicalColumn
+	assert.NoError(t, json.Unmarshal([]byte(`{"id":"`+id+`","column_type":`+columnType+`}`), &c))
+	c.Nullable = nullable
+	return &c
+}
+
+func TestParseValue(t *testing.T) {
+	integer := column(t, "count", `{"Scalar":{"column_type":{"Integer":{}}}}`, true)
+
+	v, err := ParseValue(integer, "12")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), v)
+
+	v, err = ParseValue(integer, "null")
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	_, err = ParseValue(integer, "twelve")
+	assert.ErrorContains(t, err, `count: "twelve" is not an integer`)
+
+	// Strings are kept as is, even when they look like other types
+	v, err = ParseValue(column(t, "name", `{"Scalar":{"column_type":{"String":{}}}}`, false), "null")
+	assert.NoError(t, err)
+	assert.Equal(t, "null", v)
+
+	bools := column(t, "flags", `{"Array":{"inner":{"Boolean":{}}}}`, false)
+
+	v, err = ParseValue(bools, `[true, "false"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{true, false}, v)
+
+	_, err = ParseValue(bools, "true")
+	assert.ErrorContains(t, err, "flags: must be a JSON array")
+}
+
+func TestTitle(t *testing.T) {
+	row := orderedmap.New[string, any]()
+	row.Set("type", "channel")
+	row.Set("sink", float64(123))
+	row.Set("events", []any{"a"})
+
+	assert.Equal(t, `channel -> 123 ["a"] {missing}`, Title("{type} -> {sink} {events} {missing}", row))
+}
+
+func TestParseValueTypes(t *testing.T) {
+	v, err := ParseValue(column(t, "id", `{"Scalar":{"column_type":{"Uuid":{}}}}`, false), "0F8FAD5B-D9CB-469F-A165-70867728950E")
+	assert.NoError(t, err)
+	assert.Equal(t, "0f8fad5b-d9cb-469f-a165-70867728950e", v)
+
+	_, err = ParseValue(column(t, "id", `{"Scalar":{"column_type":{"Uuid":{}}}}`, false), "123")
+	assert.ErrorContains(t, err, `id: "123" is not a UUID`)
+
+	interval := column(t, "duration", `{"Scalar":{"column_type":{"Interval":{}}}}`, false)
+
+	v, err = ParseValue(interval, "90")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90), v)
+
+	v, err = ParseValue(interval, "1h30m")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5400), v)
+
+	_, err = ParseValue(column(t, "at", `{"Scalar":{"column_type":{"TimestampTz":{}}}}`, false), "2024-01-02 15:04")
+	assert.ErrorContains(t, err, "is not an RFC 3339 time")
+
+	v, err = ParseValue(column(t, "at", `{"Scalar":{"column_type":{"Timestamp":{}}}}`, false), "2024-01-02 15:04:05")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02 15:04:05", v)
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/anti-raid/evil-befall/pkg/loc"
@@ -28,10 +29,20 @@ type StateSessionAuth struct {
 
 	// The current session index
 	CurrentSessionIndex int
+
+	// Guards the sessions as requests may be made concurrently
+	mu sync.Mutex
 }
 
 // Remove expired sessions returning the sessions removed
 func (s *StateSessionAuth) RemoveExpiredSessions() []*types.CreateUserSessionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.removeExpiredSessions()
+}
+
+func (s *StateSessionAuth) removeExpiredSessions() []*types.CreateUserSessionResponse {
 	var removed []*types.CreateUserSessionResponse
 	var removedIdx []int
 
@@ -56,7 +67,10 @@ func (s *StateSessionAuth) RemoveExpiredSessions() []*types.CreateUserSessionRes
 
 // Add a new session, returns an error if token is not set
 func (s *StateSessionAuth) AddSession(sess *types.CreateUserSessionResponse) error {
-	s.RemoveExpiredSessions() // Remove expired sessions
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpiredSessions() // Remove expired sessions
 	s.UserSessions = append(s.UserSessions, sess)
 
 	return nil
@@ -64,7 +78,10 @@ func (s *StateSessionAuth) AddSession(sess *types.CreateUserSessionResponse) err
 
 // Returns the current session
 func (s *StateSessionAuth) GetCurrentSession() (*types.CreateUserSessionResponse, error) {
-	s.RemoveExpiredSessions() // Remove expired sessions
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpiredSessions() // Remove expired sessions
 
 	if s.CurrentSessionIndex >= len(s.UserSessions) {
 		return nil, ErrSessionNotFound
@@ -73,9 +90,26 @@ func (s *StateSessionAuth) GetCurrentSession() (*types.CreateUserSessionResponse
 	return s.UserSessions[s.CurrentSessionIndex], nil
 }
 
+// Returns the current session without removing expired sessions, or nil if there is none
+//
+// This is useful for callers that run often (such as the prompt) and need to show expired sessions as such
+func (s *StateSessionAuth) PeekCurrentSession() *types.CreateUserSessionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.CurrentSessionIndex < 0 || s.CurrentSessionIndex >= len(s.UserSessions) {
+		return nil
+	}
+
+	return s.UserSessions[s.CurrentSessionIndex]
+}
+
 // Set the current session by index
 func (s *StateSessionAuth) SetCurrentSession(i int) error {
-	s.RemoveExpiredSessions() // Remove expired sessions
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpiredSessions() // Remove expired sessions
 
 	if len(s.UserSessions) > i-1 {
 		return ErrSessionNotFound
@@ -87,6 +121,9 @@ func (s *StateSessionAuth) SetCurrentSession(i int) error {
 }
 
 func (s *StateSessionAuth) RemoveSessionIfExists(sessID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, sess := range s.UserSessions {
 		if sess.SessionID == sessID {
 			s.UserSessions = append(s.UserSessions[:i], s.UserSessions[i+1:]...)
@@ -94,7 +131,7 @@ func (s *StateSessionAuth) RemoveSessionIfExists(sessID string) {
 		}
 	}
 
-	s.RemoveExpiredSessions() // Remove expired sessions
+	s.removeExpiredSessions() // Remove expired sessions
 }
 
 // Returns if the user is currently authorized into a session
@@ -109,6 +146,10 @@ func (s *StateSessionAuth) IsAuthorized() bool {
 type StateFetchOptions struct {
 	// The API URL for the Anti-Raid instance
 	InstanceAPIUrl string
+
+	// Whether requests that are not GET requests are shown instead of being sent. Not persisted so that dry-run
+	// mode never silently carries over into the next session
+	DryRun bool `json:"-"`
 }
 
 type UserPref struct {
@@ -146,6 +187,18 @@ func (s *State) SetSelectedGuild(guildID string) error {
 	return s.PersistToDisk()
 }
 
+// Returns a copy of the state that makes requests to another instance using the given sessions, the first
+// being the current one. The copy keeps the selected options but is never persisted
+func (s *State) WithInstance(instanceUrl string, sessions ...*types.CreateUserSessionResponse) *State {
+	return &State{
+		CurrentLoc:        s.CurrentLoc,
+		Session:           StateSessionAuth{UserSessions: sessions},
+		StateFetchOptions: StateFetchOptions{InstanceAPIUrl: instanceUrl, DryRun: s.StateFetchOptions.DryRun},
+		BindAddr:          s.BindAddr,
+		SelectedOptions:   s.SelectedOptions,
+	}
+}
+
 func (s *State) PersistToDisk() error {
 	// Open file
 	if s.Prefs.Persist == nil {
@@ -0,0 +1,282 @@
+// Package jsonview is a collapsible tree viewer for JSON values
+package jsonview
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/jsontree"
+	"github.com/anti-raid/evil-befall/pkg/state"
+	"github.com/anti-raid/evil-befall/pkg/tui"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const help = "Enter toggle  e/x expand/collapse all  / search  n/N next/prev match  c copy path  s save subtree  q quit"
+
+// Nodes with at most this many children in total are expanded when the viewer opens
+const autoExpandLimit = 50
+
+// Viewer shows a JSON tree. Tree nodes are created lazily when their parent is first expanded so large values
+// open quickly
+type Viewer struct {
+	app    *tview.Application
+	tree   *tview.TreeView
+	status *tview.TextView
+	prompt *tview.InputField
+	layout *tview.Flex
+
+	// The tree nodes created so far
+	nodes map[*jsontree.Node]*tview.TreeNode
+
+	matches []*jsontree.Node
+	match   int
+}
+
+// Run shows the viewer until the user quits
+func Run(state *state.State, title string, root *jsontree.Node) error {
+	v := &Viewer{
+		app:    tui.NewTview(state),
+		tree:   tview.NewTreeView(),
+		status: tview.NewTextView().SetDynamicColors(true),
+		prompt: tview.NewInputField(),
+		nodes:  map[*jsontree.Node]*tview.TreeNode{},
+	}
+
+	v.tree.SetBorder(true).SetTitle(" " + title + " ")
+
+	rootNode := v.node(root)
+	v.tree.SetRoot(rootNode).SetCurrentNode(rootNode)
+	v.expand(root)
+
+	if count(root) <= autoExpandLimit {
+		v.expandAll(root)
+	}
+
+	v.tree.SetSelectedFunc(func(n *tview.TreeNode) {
+		if n.IsExpanded() {
+			n.Collapse()
+			return
+		}
+
+		v.expand(n.GetReference().(*jsontree.Node))
+	})
+
+	v.tree.SetInputCapture(v.handleKey)
+
+	v.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.tree, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.setStatus(help)
+
+	return v.app.SetRoot(v.layout, true).Run()
+}
+
+func count(n *jsontree.Node) int {
+	total := len(n.Children)
+
+	for _, c := range n.Children {
+		total += count(c)
+	}
+
+	return total
+}
+
+// Returns the tree node of a JSON node, creating it if needed
+func (v *Viewer) node(n *jsontree.Node) *tview.TreeNode {
+	if tn, ok := v.nodes[n]; ok {
+		return tn
+	}
+
+	text := n.Summary()
+	if n.Parent != nil {
+		text = n.Key + ": " + text
+	}
+
+	tn := tview.NewTreeNode(tview.Escape(text)).SetReference(n).SetExpanded(false)
+
+	switch {
+	case n.Kind != jsontree.KindScalar:
+		tn.SetColor(tcell.ColorTeal)
+	case strings.HasPrefix(n.Scalar, `"`):
+		tn.SetColor(tcell.ColorGreen)
+	case n.Scalar == "null":
+		tn.SetColor(tcell.ColorGray)
+	default:
+		tn.SetColor(tcell.ColorYellow)
+	}
+
+	v.nodes[n] = tn
+
+	return tn
+}
+
+// Expands a node, creating the tree nodes of its children the first time
+func (v *Viewer) expand(n *jsontree.Node) {
+	tn := v.node(n)
+
+	if len(tn.GetChildren()) != len(n.Children) {
+		tn.ClearChildren()
+
+		for _, c := range n.Children {
+			tn.AddChild(v.node(c))
+		}
+	}
+
+	tn.Expand()
+}
+
+func (v *Viewer) expandAll(n *jsontree.Node) {
+	v.expand(n)
+
+	for _, c := range n.Children {
+		if c.Kind != jsontree.KindScalar {
+			v.expandAll(c)
+		}
+	}
+}
+
+// Selects a node, expanding its ancestors
+func (v *Viewer) reveal(n *jsontree.Node) {
+	for p := n.Parent; p != nil; p = p.Parent {
+		v.expand(p)
+	}
+
+	v.tree.SetCurrentNode(v.node(n))
+}
+
+func (v *Viewer) current() *jsontree.Node {
+	tn := v.tree.GetCurrentNode()
+
+	if tn == nil {
+		return nil
+	}
+
+	return tn.GetReference().(*jsontree.Node)
+}
+
+func (v *Viewer) setStatus(text string) {
+	v.status.SetText(text)
+}
+
+func (v *Viewer) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	n := v.current()
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		v.app.Stop()
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch event.Rune() {
+	case 'q':
+		v.app.Stop()
+	case 'e':
+		if n != nil {
+			v.expandAll(n)
+		}
+	case 'x':
+		if n != nil {
+			v.node(n).CollapseAll()
+		}
+	case '/':
+		v.ask("Search: ", func(query string) {
+			v.search(query)
+		})
+	case 'n':
+		v.next(1)
+	case 'N':
+		v.next(-1)
+	case 'c':
+		if n != nil {
+			v.copyPath(n)
+		}
+	case 's':
+		if n != nil {
+			v.ask("Save "+n.PathString()+" to: ", func(file string) {
+				v.save(n, file)
+			})
+		}
+	default:
+		return event
+	}
+
+	return nil
+}
+
+// Shows a prompt in place of the status line, calling done with the input if it is submitted
+func (v *Viewer) ask(label string, done func(text string)) {
+	v.prompt.SetLabel(label).SetText("")
+	v.layout.RemoveItem(v.status).AddItem(v.prompt, 1, 0, true)
+	v.app.SetFocus(v.prompt)
+
+	v.prompt.SetDoneFunc(func(key tcell.Key) {
+		v.layout.RemoveItem(v.prompt).AddItem(v.status, 1, 0, false)
+		v.app.SetFocus(v.tree)
+
+		text := strings.TrimSpace(v.prompt.GetText())
+
+		if key != tcell.KeyEnter || text == "" {
+			v.setStatus(help)
+			return
+		}
+
+		done(text)
+	})
+}
+
+func (v *Viewer) search(query string) {
+	v.matches = v.tree.GetRoot().GetReference().(*jsontree.Node).Search(query)
+	v.match = -1
+
+	if len(v.matches) == 0 {
+		v.setStatus(fmt.Sprintf("[red]No matches for %s", tview.Escape(query)))
+		return
+	}
+
+	v.next(1)
+}
+
+func (v *Viewer) next(step int) {
+	if len(v.matches) == 0 {
+		v.setStatus("[red]No search, press / to search")
+		return
+	}
+
+	v.match = (v.match + step + len(v.matches)) % len(v.matches)
+	n := v.matches[v.match]
+
+	v.reveal(n)
+	v.setStatus(fmt.Sprintf("Match %d/%d: %s", v.match+1, len(v.matches), tview.Escape(n.PathString())))
+}
+
+// Copies the path of a node to the clipboard using the OSC 52 terminal sequence, which most terminals support
+// (including over SSH). The path is also shown in the status line in case the terminal does not
+func (v *Viewer) copyPath(n *jsontree.Node) {
+	path := n.PathString()
+
+	fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(path)))
+
+	v.setStatus("Copied " + tview.Escape(path))
+}
+
+func (v *Viewer) save(n *jsontree.Node, file string) {
+	b, err := n.Indent()
+
+	if err == nil {
+		err = os.WriteFile(file, append(b, '\n'), 0644)
+	}
+
+	if err != nil {
+		v.setStatus("[red]Failed to save: " + tview.Escape(err.Error()))
+		return
+	}
+
+	v.setStatus(fmt.Sprintf("Saved %s to %s", tview.Escape(n.PathString()), tview.Escape(file)))
+}
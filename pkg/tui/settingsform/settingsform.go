@@ -0,0 +1,300 @@
+// Package settingsform builds tview forms for the rows of a setting from the columns of its CanonicalConfigOption
+package settingsform
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anti-raid/evil-befall/pkg/settings"
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	"github.com/rivo/tview"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// The dropdown option for setting a nullable column to null
+const nullOption = "(null)"
+
+// How many lines text areas are
+const textAreaHeight = 5
+
+var errRequired = errors.New("is required")
+
+// editor produces the JSON value of a column
+type editor interface {
+	value() (any, error)
+}
+
+// Form edits the columns of a row for an operation
+type Form struct {
+	Form *tview.Form
+
+	columns []*silverpelt.CanonicalColumn
+	editors []editor
+}
+
+// Columns returns the columns sent for an operation: those that are not ignored for it and not set by the server
+// through ColumnsToSet. The primary key is always sent for updates and deletes as it identifies the row
+func Columns(opt *silverpelt.CanonicalConfigOption, op silverpelt.CanonicalOperationType) []*silverpelt.CanonicalColumn {
+	toSet := columnsToSet(opt, op)
+
+	var columns []*silverpelt.CanonicalColumn
+
+	for i := range opt.Columns {
+		c := &opt.Columns[i]
+
+		if c.ID == opt.PrimaryKey && (op == silverpelt.Update || op == silverpelt.Delete) {
+			columns = append(columns, c)
+			continue
+		}
+
+		if _, ok := toSet.Get(c.ID); ok || c.IsIgnoredFor(op) {
+			continue
+		}
+
+		columns = append(columns, c)
+	}
+
+	return columns
+}
+
+func columnsToSet(opt *silverpelt.CanonicalConfigOption, op silverpelt.CanonicalOperationType) *orderedmap.OrderedMap[string, string] {
+	if specific, ok := opt.Operations.Get(op); ok {
+		return &specific.ColumnsToSet
+	}
+
+	return orderedmap.New[string, string]()
+}
+
+// New builds a form for an operation on a row of a setting. The values of row, if given, are filled in
+//
+// Deletes only show the row. The primary key cannot be changed by updates
+func New(opt *silverpelt.CanonicalConfigOption, op silverpelt.CanonicalOperationType, row *orderedmap.OrderedMap[string, any]) *Form {
+	f := &Form{Form: tview.NewForm(), columns: Columns(opt, op)}
+	f.Form.SetBorder(true).SetTitle(fmt.Sprintf(" %s %s ", op, opt.Name))
+
+	for _, c := range f.columns {
+		var current any
+		var ok bool
+
+		if row != nil {
+			current, ok = row.Get(c.ID)
+		}
+
+		e, item := newEditor(c, current, ok)
+		f.editors = append(f.editors, e)
+
+		if op == silverpelt.Delete || (op == silverpelt.Update && c.ID == opt.PrimaryKey) {
+			item.SetDisabled(true)
+		}
+
+		f.Form.AddFormItem(item)
+	}
+
+	// Columns set by the server are shown so it is clear why they cannot be edited
+	toSet := columnsToSet(opt, op)
+
+	for pair := toSet.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+
+		if c, ok := opt.Column(pair.Key); ok {
+			name = c.Name
+		}
+
+		f.Form.AddFormItem(tview.NewInputField().SetLabel(name).SetText("set by the server to " + pair.Value).SetDisabled(true))
+	}
+
+	return f
+}
+
+// Value returns the fields of the row, in column order
+func (f *Form) Value() (*orderedmap.OrderedMap[string, any], error) {
+	fields := orderedmap.New[string, any]()
+
+	for i, e := range f.editors {
+		v, err := e.value()
+
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", f.columns[i].Name, err)
+		}
+
+		fields.Set(f.columns[i].ID, v)
+	}
+
+	return fields, nil
+}
+
+func label(c *silverpelt.CanonicalColumn) string {
+	l := c.Name
+
+	if !c.Nullable {
+		l += "*"
+	}
+
+	if c.Unique {
+		l += " (unique)"
+	}
+
+	return l
+}
+
+func placeholder(c *silverpelt.CanonicalColumn) string {
+	inner, array := c.ColumnType.Inner()
+
+	var p string
+
+	switch {
+	case array:
+		p = "JSON array of " + inner.Name() + "s"
+	case inner.Uuid != nil:
+		p = "UUID"
+	case inner.TimestampTz != nil:
+		p = "e.g. 2024-01-02T15:04:05Z"
+	case inner.Timestamp != nil:
+		p = "e.g. 2024-01-02T15:04:05"
+	case inner.Interval != nil:
+		p = "seconds or a duration such as 1h30m"
+	case inner.Json != nil:
+		p = "JSON"
+	default:
+		p = inner.Name()
+	}
+
+	if c.Description != "" {
+		p += ": " + c.Description
+	}
+
+	return p
+}
+
+// Returns the text of a value in an input, with null as an empty input
+func text(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	return settings.FormatValue(v)
+}
+
+// Returns the editor of a column and its form item, filled in with the current value if set
+func newEditor(c *silverpelt.CanonicalColumn, current any, set bool) (editor, tview.FormItem) {
+	inner, array := c.ColumnType.Inner()
+
+	switch {
+	case !array && inner.String != nil && len(inner.String.AllowedValues) > 0:
+		return dropdown(c, inner.String.AllowedValues, current, set)
+	case !array && inner.Boolean != nil && c.Nullable:
+		return dropdown(c, []string{"true", "false"}, current, set)
+	case !array && inner.Boolean != nil:
+		checkbox := tview.NewCheckbox().SetLabel(label(c))
+
+		if b, ok := current.(bool); ok {
+			checkbox.SetChecked(b)
+		}
+
+		return &boolEditor{checkbox: checkbox}, checkbox
+	case !array && (inner.Json != nil || (inner.String != nil && inner.String.Kind.Textarea != nil)):
+		area := tview.NewTextArea().SetLabel(label(c)).SetPlaceholder(placeholder(c)).SetSize(textAreaHeight, 0)
+
+		if set {
+			area.SetText(text(current), false)
+		}
+
+		return &textEditor{column: c, text: area.GetText}, area
+	}
+
+	input := tview.NewInputField().SetLabel(label(c)).SetPlaceholder(placeholder(c))
+
+	if !array {
+		switch {
+		case inner.Integer != nil || inner.BitFlag != nil:
+			input.SetAcceptanceFunc(tview.InputFieldInteger)
+		case inner.Float != nil:
+			input.SetAcceptanceFunc(tview.InputFieldFloat)
+		case inner.String != nil && inner.String.MaxLength != nil:
+			input.SetAcceptanceFunc(tview.InputFieldMaxLength(*inner.String.MaxLength))
+		}
+	}
+
+	if c.Secret {
+		input.SetMaskCharacter('*')
+	}
+
+	if set {
+		input.SetText(text(current))
+	}
+
+	return &textEditor{column: c, text: input.GetText}, input
+}
+
+func dropdown(c *silverpelt.CanonicalColumn, options []string, current any, set bool) (editor, tview.FormItem) {
+	if c.Nullable {
+		options = append([]string{nullOption}, options...)
+	}
+
+	d := tview.NewDropDown().SetLabel(label(c)).SetOptions(options, nil)
+
+	if i := slices.Index(options, text(current)); set && i != -1 {
+		d.SetCurrentOption(i)
+	} else if c.Nullable && (!set || current == nil) {
+		d.SetCurrentOption(0)
+	}
+
+	return &dropdownEditor{column: c, dropdown: d}, d
+}
+
+// Edits a column as text, parsing it with settings.ParseValue
+type textEditor struct {
+	column *silverpelt.CanonicalColumn
+	text   func() string
+}
+
+func (e *textEditor) value() (any, error) {
+	text := e.text()
+	inner, array := e.column.ColumnType.Inner()
+
+	// Empty strings are valid values of string columns, other columns are null when empty
+	if strings.TrimSpace(text) == "" && (array || inner.String == nil || e.column.Nullable) {
+		if !e.column.Nullable {
+			return nil, errRequired
+		}
+
+		return nil, nil
+	}
+
+	v, err := settings.ParseValue(e.column, text)
+
+	if err != nil {
+		// ParseValue prefixes errors with the column ID, which Form.Value replaces with its name
+		return nil, errors.New("is invalid: " + strings.TrimPrefix(err.Error(), e.column.ID+": "))
+	}
+
+	return v, nil
+}
+
+type boolEditor struct {
+	checkbox *tview.Checkbox
+}
+
+func (e *boolEditor) value() (any, error) {
+	return e.checkbox.IsChecked(), nil
+}
+
+type dropdownEditor struct {
+	column   *silverpelt.CanonicalColumn
+	dropdown *tview.DropDown
+}
+
+func (e *dropdownEditor) value() (any, error) {
+	i, option := e.dropdown.GetCurrentOption()
+
+	switch {
+	case i == -1:
+		return nil, errRequired
+	case option == nullOption:
+		return nil, nil
+	}
+
+	return settings.ParseValue(e.column, option)
+}
@@ -0,0 +1,109 @@
+package settingsform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anti-raid/evil-befall/types/silverpelt"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const testOption = `{
+	"id": "sinks",
+	"name": "Sinks",
+	"primary_key": "id",
+	"columns": [
+		{"id": "id", "name": "ID", "column_type": {"Scalar": {"column_type": {"Uuid": {}}}}, "ignored_for": ["Create"]},
+		{"id": "guild_id", "name": "Guild", "column_type": {"Scalar": {"column_type": {"String": {}}}}, "ignored_for": ["View", "Create", "Update"]},
+		{"id": "type", "name": "Type", "column_type": {"Scalar": {"column_type": {"String": {"allowed_values": ["channel", "discordhook"]}}}}},
+		{"id": "count", "name": "Count", "nullable": true, "column_type": {"Scalar": {"column_type": {"Integer": {}}}}},
+		{"id": "broken", "name": "Broken", "column_type": {"Scalar": {"column_type": {"Boolean": {}}}}},
+		{"id": "created_by", "name": "Created By", "column_type": {"Scalar": {"column_type": {"String": {}}}}}
+	],
+	"operations": {
+		"Create": {"columns_to_set": {"created_by": "{__author}"}},
+		"Update": {},
+		"Delete": {}
+	}
+}`
+
+func option(t *testing.T) *silverpelt.CanonicalConfigOption {
+	var opt silverpelt.CanonicalConfigOption
+	assert.NoError(t, json.Unmarshal([]byte(testOption), &opt))
+	return &opt
+}
+
+func columnIDs(columns []*silverpelt.CanonicalColumn) []string {
+	var ids []string
+	for _, c := range columns {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestColumns(t *testing.T) {
+	opt := option(t)
+
+	assert.Equal(t, []string{"type", "count", "broken"}, columnIDs(Columns(opt, silverpelt.Create)))
+	assert.Equal(t, []string{"id", "type", "count", "broken", "created_by"}, columnIDs(Columns(opt, silverpelt.Update)))
+
+	// The primary key identifies the row even though it is ignored for deletes
+	opt.Columns[0].IgnoredFor = append(opt.Columns[0].IgnoredFor, silverpelt.Delete)
+	assert.Equal(t, []string{"id", "guild_id", "type", "count", "broken", "created_by"}, columnIDs(Columns(opt, silverpelt.Delete)))
+}
+
+func TestValue(t *testing.T) {
+	opt := option(t)
+
+	f := New(opt, silverpelt.Create, nil)
+
+	// Type has no option selected yet
+	_, err := f.Value()
+	assert.ErrorContains(t, err, "Type is required")
+
+	f.editors[0].(*dropdownEditor).dropdown.SetCurrentOption(1)
+
+	v, err := f.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"type", "count", "broken"}, keys(v))
+	assert.Equal(t, "discordhook", get(v, "type"))
+	assert.Nil(t, get(v, "count"))
+	assert.Equal(t, false, get(v, "broken"))
+
+	row := orderedmap.New[string, any]()
+	row.Set("id", "0f8fad5b-d9cb-469f-a165-70867728950e")
+	row.Set("type", "channel")
+	row.Set("count", float64(3))
+	row.Set("broken", true)
+	row.Set("created_by", "123")
+
+	f = New(opt, silverpelt.Update, row)
+
+	v, err = f.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "0f8fad5b-d9cb-469f-a165-70867728950e", get(v, "id"))
+	assert.Equal(t, "channel", get(v, "type"))
+	assert.Equal(t, int64(3), get(v, "count"))
+	assert.Equal(t, true, get(v, "broken"))
+	assert.Equal(t, "123", get(v, "created_by"))
+
+	f.Form.GetFormItem(2).(*tview.InputField).SetText("three")
+
+	_, err = f.Value()
+	assert.ErrorContains(t, err, `Count is invalid: "three" is not an integer`)
+}
+
+func keys(m *orderedmap.OrderedMap[string, any]) []string {
+	var k []string
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		k = append(k, pair.Key)
+	}
+	return k
+}
+
+func get(m *orderedmap.OrderedMap[string, any], key string) any {
+	v, _ := m.Get(key)
+	return v
+}
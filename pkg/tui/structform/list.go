@@ -0,0 +1,171 @@
+package structform
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// The key of the single field of list items that are not structs
+const itemValueKey = "value"
+
+type listItem struct {
+	obj *Object
+
+	// Whether the item is a scalar, kept as the value field of obj
+	scalar bool
+}
+
+func (i *listItem) value() (any, bool, error) {
+	v, err := i.obj.Value()
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if i.scalar {
+		val, ok := v[itemValueKey]
+		return val, ok, nil
+	}
+
+	return v, true, nil
+}
+
+type listEditor struct {
+	items []*listItem
+}
+
+func (e *listEditor) value() (any, bool, error) {
+	if len(e.items) == 0 {
+		return nil, false, nil
+	}
+
+	arr := make([]any, 0, len(e.items))
+
+	for i, item := range e.items {
+		v, _, err := item.value()
+
+		if err != nil {
+			return nil, false, fmt.Errorf("item %d: %w", i+1, err)
+		}
+
+		arr = append(arr, v)
+	}
+
+	return arr, true, nil
+}
+
+// Adds a link to a page listing the items of a slice, with a page per item to edit it
+func (b *Builder) list(parent *tview.Form, parentPage, path string, f Field, elem reflect.Type) editor {
+	e := &listEditor{}
+
+	items := tview.NewList().ShowSecondaryText(false)
+	items.SetBorder(true).SetTitle(" " + path + " (Enter to edit an item, Tab for the buttons) ")
+
+	buttons := tview.NewForm().SetHorizontal(true)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(items, 0, 1, true).
+		AddItem(buttons, 3, 0, false)
+
+	page := b.addPage(layout)
+	link := b.link(parent, f, page)
+
+	refresh := func() {
+		current := items.GetCurrentItem()
+		items.Clear()
+
+		for i, item := range e.items {
+			items.AddItem(fmt.Sprintf("%d. %s", i+1, summarize(item)), "", 0, nil)
+		}
+
+		if current < len(e.items) {
+			items.SetCurrentItem(current)
+		}
+
+		link.SetText(summarize(e))
+	}
+
+	isStruct := elem.Kind() == reflect.Struct || (elem.Kind() == reflect.Ptr && elem.Elem().Kind() == reflect.Struct)
+
+	newItem := func() *listItem {
+		title := fmt.Sprintf("%s[%d]", path, len(e.items))
+
+		item := &listItem{scalar: !isStruct}
+
+		if isStruct {
+			item.obj = b.Object(title, StructFields(elem))
+		} else {
+			item.obj = b.Object(title, []Field{{Key: itemValueKey, Label: "Value", Type: elem, Required: true}})
+		}
+
+		done := func() {
+			refresh()
+			b.Show(page)
+		}
+
+		item.obj.Form.AddButton("Done", done)
+		item.obj.Form.SetCancelFunc(done)
+
+		return item
+	}
+
+	items.SetSelectedFunc(func(i int, _, _ string, _ rune) {
+		b.Show(e.items[i].obj.Page)
+	})
+
+	buttons.AddButton("Add", func() {
+		item := newItem()
+		e.items = append(e.items, item)
+		refresh()
+		items.SetCurrentItem(len(e.items) - 1)
+		b.Show(item.obj.Page)
+	})
+
+	buttons.AddButton("Remove", func() {
+		i := items.GetCurrentItem()
+
+		if i < 0 || i >= len(e.items) {
+			return
+		}
+
+		b.Pages.RemovePage(e.items[i].obj.Page)
+		e.items = slices.Delete(e.items, i, i+1)
+		refresh()
+	})
+
+	buttons.AddButton("Done", func() {
+		refresh()
+		b.Show(parentPage)
+	})
+
+	buttons.SetCancelFunc(func() {
+		b.Show(parentPage)
+	})
+
+	// The list and the buttons are separate primitives, so moving between them is done here
+	if b.App != nil {
+		items.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyTab {
+				b.App.SetFocus(buttons)
+				return nil
+			}
+
+			return event
+		})
+
+		buttons.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyBacktab || event.Key() == tcell.KeyUp {
+				b.App.SetFocus(items)
+				return nil
+			}
+
+			return event
+		})
+	}
+
+	return e
+}
@@ -0,0 +1,427 @@
+// Package structform builds tview forms for editing values of arbitrary Go types using reflection
+//
+// Values are produced in their JSON form (keyed by the json tag names of struct fields), making them suitable for
+// TestableRoute.PopulateWithArgs
+package structform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anti-raid/evil-befall/pkg/api"
+	"github.com/anti-raid/evil-befall/pkg/api/openapi"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	enumListType      = reflect.TypeOf((*interface{ List() []string })(nil)).Elem()
+)
+
+// The dropdown option for leaving an optional enum unset
+const unsetOption = "(unset)"
+
+// How many lines JSON text areas are
+const jsonAreaHeight = 5
+
+// A field of a struct being edited
+type Field struct {
+	// The JSON key of the field
+	Key string
+
+	Label       string
+	Type        reflect.Type
+	Required    bool
+	Description string
+}
+
+// editor produces the JSON value of a form item
+type editor interface {
+	// Returns the value and whether it is set. Unset values are left out of their parent object, decoding to the
+	// zero value of their field
+	value() (any, bool, error)
+}
+
+// Builder builds forms into a set of pages, with nested structs and lists getting their own page
+type Builder struct {
+	App   *tview.Application
+	Pages *tview.Pages
+
+	// Called whenever the current page changes, e.g. to update a help line
+	OnNavigate func(page string)
+
+	pageCount int
+
+	// The struct types currently being built, recursive types are edited as JSON instead
+	building map[reflect.Type]bool
+}
+
+func NewBuilder(app *tview.Application) *Builder {
+	return &Builder{App: app, Pages: tview.NewPages(), building: map[reflect.Type]bool{}}
+}
+
+// RequestFields returns the form fields of the request type of a route, using the full request field keys
+// (e.g. path:guildId)
+func RequestFields(reqType reflect.Type) []Field {
+	var fields []Field
+
+	for _, f := range api.RequestFields(reqType) {
+		fields = append(fields, Field{Key: f.Key, Label: f.Key, Type: f.Type, Required: f.Required(), Description: f.Description})
+	}
+
+	return fields
+}
+
+// Returns the form fields of a struct type from its JSON properties
+func StructFields(t reflect.Type) []Field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []Field
+
+	for _, p := range openapi.Properties(t) {
+		fields = append(fields, Field{Key: p.Name, Label: p.Name, Type: p.Type, Required: p.Required, Description: p.Description})
+	}
+
+	return fields
+}
+
+// Object is a form editing a set of fields, producing a JSON object
+type Object struct {
+	Form *tview.Form
+
+	// The name of the page showing the form
+	Page string
+
+	keys    []string
+	editors []editor
+}
+
+// Value returns the JSON object of the form, with unset optional fields left out
+func (o *Object) Value() (map[string]any, error) {
+	obj := map[string]any{}
+
+	for i, e := range o.editors {
+		v, set, err := e.value()
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", o.keys[i], err)
+		}
+
+		if set {
+			obj[o.keys[i]] = v
+		}
+	}
+
+	return obj, nil
+}
+
+func (o *Object) value() (any, bool, error) {
+	v, err := o.Value()
+	return v, len(v) > 0, err
+}
+
+// Object builds a form for the given fields
+func (b *Builder) Object(title string, fields []Field) *Object {
+	o := &Object{Form: tview.NewForm()}
+	o.Form.SetBorder(true).SetTitle(" " + title + " ")
+	o.Page = b.addPage(o.Form)
+
+	for _, f := range fields {
+		o.keys = append(o.keys, f.Key)
+		o.editors = append(o.editors, b.addField(o.Form, o.Page, title+"."+f.Key, f))
+	}
+
+	return o
+}
+
+// Adds a page, returning its name
+func (b *Builder) addPage(p tview.Primitive) string {
+	b.pageCount++
+	name := "structform-" + strconv.Itoa(b.pageCount)
+	b.Pages.AddPage(name, p, true, false)
+	return name
+}
+
+// Shows a page. This is queued as the form moves focus to its next item after a field is submitted
+func (b *Builder) Show(page string) {
+	show := func() {
+		b.Pages.SwitchToPage(page)
+
+		if b.OnNavigate != nil {
+			b.OnNavigate(page)
+		}
+	}
+
+	if b.App == nil {
+		show()
+		return
+	}
+
+	go b.App.QueueUpdateDraw(show)
+}
+
+func label(f Field) string {
+	if f.Required {
+		return f.Label + "*"
+	}
+
+	return f.Label
+}
+
+// Adds the form item(s) for a field, returning its editor
+func (b *Builder) addField(form *tview.Form, page, path string, f Field) editor {
+	t := f.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		input := b.input(form, f, "RFC 3339, e.g. 2024-01-02T15:04:05Z")
+		return &scalarEditor{input: input, parse: func(s string) (any, error) {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, errors.New("must be an RFC 3339 time")
+			}
+
+			return s, nil
+		}}
+	case t.Implements(enumListType) && t.Kind() == reflect.String:
+		return b.enum(form, f, reflect.Zero(t).Interface().(interface{ List() []string }).List())
+	case reflect.PointerTo(t).Implements(jsonMarshalerType) || t.Implements(jsonMarshalerType):
+		// Types with custom JSON forms cannot be edited field by field
+		return b.json(form, f)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		input := b.input(form, f, f.Description)
+		return &scalarEditor{input: input, parse: func(s string) (any, error) { return s, nil }}
+	case reflect.Bool:
+		checkbox := tview.NewCheckbox().SetLabel(label(f))
+		form.AddFormItem(checkbox)
+		return &boolEditor{checkbox: checkbox}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		input := b.input(form, f, f.Description).SetAcceptanceFunc(tview.InputFieldInteger)
+		bits := t.Bits()
+		return &scalarEditor{input: input, parse: func(s string) (any, error) {
+			if _, err := strconv.ParseInt(s, 10, bits); err != nil {
+				return nil, fmt.Errorf("must be a %d-bit integer", bits)
+			}
+
+			return json.Number(s), nil
+		}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		input := b.input(form, f, f.Description).SetAcceptanceFunc(func(text string, ch rune) bool {
+			return ch >= '0' && ch <= '9'
+		})
+		bits := t.Bits()
+		return &scalarEditor{input: input, parse: func(s string) (any, error) {
+			if _, err := strconv.ParseUint(s, 10, bits); err != nil {
+				return nil, fmt.Errorf("must be an unsigned %d-bit integer", bits)
+			}
+
+			return json.Number(s), nil
+		}}
+	case reflect.Float32, reflect.Float64:
+		input := b.input(form, f, f.Description).SetAcceptanceFunc(tview.InputFieldFloat)
+		return &scalarEditor{input: input, parse: func(s string) (any, error) {
+			if _, err := strconv.ParseFloat(s, t.Bits()); err != nil {
+				return nil, errors.New("must be a number")
+			}
+
+			return json.Number(s), nil
+		}}
+	case reflect.Struct:
+		if b.building[t] {
+			return b.json(form, f)
+		}
+
+		b.building[t] = true
+		child := b.Object(path, StructFields(t))
+		delete(b.building, t)
+
+		return b.nested(form, page, f, child)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 strings in JSON
+			input := b.input(form, f, "base64")
+			return &scalarEditor{input: input, parse: func(s string) (any, error) { return s, nil }}
+		}
+
+		return b.list(form, page, path, f, t.Elem())
+	}
+
+	// Maps and interfaces
+	return b.json(form, f)
+}
+
+func (b *Builder) input(form *tview.Form, f Field, placeholder string) *tview.InputField {
+	input := tview.NewInputField().SetLabel(label(f)).SetPlaceholder(placeholder)
+	form.AddFormItem(input)
+	return input
+}
+
+func (b *Builder) enum(form *tview.Form, f Field, options []string) editor {
+	if !f.Required {
+		options = append([]string{unsetOption}, options...)
+	}
+
+	dropdown := tview.NewDropDown().SetLabel(label(f)).SetOptions(options, nil).SetCurrentOption(0)
+	form.AddFormItem(dropdown)
+
+	return &enumEditor{dropdown: dropdown}
+}
+
+func (b *Builder) json(form *tview.Form, f Field) editor {
+	placeholder := "JSON"
+	if f.Description != "" {
+		placeholder += ": " + f.Description
+	}
+
+	area := tview.NewTextArea().SetLabel(label(f)).SetPlaceholder(placeholder).SetSize(jsonAreaHeight, 0)
+	form.AddFormItem(area)
+
+	return &jsonEditor{area: area}
+}
+
+// Adds a read-only field that opens another page when submitted, showing a summary of its value
+func (b *Builder) link(form *tview.Form, f Field, page string) *tview.InputField {
+	input := tview.NewInputField().SetLabel(label(f)).SetPlaceholder("press Enter to edit")
+	input.SetAcceptanceFunc(func(string, rune) bool { return false })
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			b.Show(page)
+		}
+	})
+
+	form.AddFormItem(input)
+
+	return input
+}
+
+// Adds a link to a page editing a nested struct
+func (b *Builder) nested(parent *tview.Form, parentPage string, f Field, child *Object) editor {
+	link := b.link(parent, f, child.Page)
+
+	back := func() {
+		link.SetText(summarize(child))
+		b.Show(parentPage)
+	}
+
+	child.Form.AddButton("Done", back)
+	child.Form.SetCancelFunc(back)
+
+	return &nestedEditor{child: child}
+}
+
+func summarize(e editor) string {
+	v, set, err := e.value()
+
+	switch {
+	case err != nil:
+		return "invalid: " + err.Error()
+	case !set:
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+type scalarEditor struct {
+	input *tview.InputField
+	parse func(s string) (any, error)
+}
+
+func (e *scalarEditor) value() (any, bool, error) {
+	text := e.input.GetText()
+
+	if text == "" {
+		return nil, false, nil
+	}
+
+	v, err := e.parse(text)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return v, true, nil
+}
+
+type boolEditor struct {
+	checkbox *tview.Checkbox
+}
+
+func (e *boolEditor) value() (any, bool, error) {
+	checked := e.checkbox.IsChecked()
+	return checked, checked, nil
+}
+
+type enumEditor struct {
+	dropdown *tview.DropDown
+}
+
+func (e *enumEditor) value() (any, bool, error) {
+	_, option := e.dropdown.GetCurrentOption()
+
+	if option == unsetOption {
+		return nil, false, nil
+	}
+
+	return option, true, nil
+}
+
+type jsonEditor struct {
+	area *tview.TextArea
+}
+
+func (e *jsonEditor) value() (any, bool, error) {
+	text := strings.TrimSpace(e.area.GetText())
+
+	if text == "" {
+		return nil, false, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return v, true, nil
+}
+
+type nestedEditor struct {
+	child *Object
+}
+
+func (e *nestedEditor) value() (any, bool, error) {
+	v, err := e.child.Value()
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return v, len(v) > 0, nil
+}
@@ -0,0 +1,82 @@
+package structform
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testInner struct {
+	Name string `json:"name"`
+}
+
+type testValue struct {
+	Title   string      `json:"title"`
+	Count   int32       `json:"count,omitempty"`
+	Enabled bool        `json:"enabled,omitempty"`
+	Inner   testInner   `json:"inner"`
+	Tags    []string    `json:"tags,omitempty"`
+	Items   []testInner `json:"items,omitempty"`
+	Extra   any         `json:"extra,omitempty"`
+	Self    *testValue  `json:"self,omitempty"`
+}
+
+func TestObjectValue(t *testing.T) {
+	b := NewBuilder(nil)
+	o := b.Object("test", StructFields(reflect.TypeOf(testValue{})))
+
+	// Fields are left out until filled in
+	v, err := o.Value()
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+
+	e := editors(o)
+
+	e["title"].(*scalarEditor).input.SetText("hello")
+	e["count"].(*scalarEditor).input.SetText("12")
+	e["enabled"].(*boolEditor).checkbox.SetChecked(true)
+	e["inner"].(*nestedEditor).child.editors[0].(*scalarEditor).input.SetText("nested")
+	e["extra"].(*jsonEditor).area.SetText(`{"a": 1}`, false)
+
+	// Recursive types are edited as JSON below the first level
+	self := editors(e["self"].(*nestedEditor).child)
+	assert.IsType(t, &jsonEditor{}, self["self"])
+
+	tags := e["tags"].(*listEditor)
+	for _, tag := range []string{"a", "b"} {
+		item := &listItem{obj: b.Object("tag", []Field{{Key: itemValueKey, Type: reflect.TypeOf(""), Required: true}}), scalar: true}
+		item.obj.editors[0].(*scalarEditor).input.SetText(tag)
+		tags.items = append(tags.items, item)
+	}
+
+	v, err = o.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"title":   "hello",
+		"count":   json.Number("12"),
+		"enabled": true,
+		"inner":   map[string]any{"name": "nested"},
+		"tags":    []any{"a", "b"},
+		"extra":   map[string]any{"a": json.Number("1")},
+	}, v)
+
+	e["count"].(*scalarEditor).input.SetText("99999999999")
+	_, err = o.Value()
+	assert.ErrorContains(t, err, "count: must be a 32-bit integer")
+
+	e["count"].(*scalarEditor).input.SetText("")
+	e["extra"].(*jsonEditor).area.SetText(`{`, false)
+	_, err = o.Value()
+	assert.ErrorContains(t, err, "extra: invalid JSON")
+}
+
+func editors(o *Object) map[string]editor {
+	m := map[string]editor{}
+	for i, k := range o.keys {
+		m[k] = o.editors[i]
+	}
+
+	return m
+}
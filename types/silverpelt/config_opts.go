@@ -1,6 +1,8 @@
 package silverpelt
 
 import (
+	"slices"
+
 	"github.com/anti-raid/evil-befall/types/discordgo"
 	"github.com/anti-raid/evil-befall/types/ext_types"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -173,3 +175,89 @@ type CanonicalConfigOption struct {
 	MaxEntries           uint64                                                                               `json:"max_entries"`
 	Operations           orderedmap.OrderedMap[CanonicalOperationType, CanonicalOperationSpecific]            `json:"operations"`
 }
+
+// Returns the inner type of a column and whether the column is an array of it
+func (c CanonicalColumnType) Inner() (CanonicalInnerColumnType, bool) {
+	if c.Array != nil {
+		return c.Array.Inner, true
+	}
+
+	if c.Scalar != nil {
+		return c.Scalar.ColumnType, false
+	}
+
+	return CanonicalInnerColumnType{}, false
+}
+
+// Returns the name of an inner column type, e.g. string or integer
+func (t CanonicalInnerColumnType) Name() string {
+	switch {
+	case t.Uuid != nil:
+		return "uuid"
+	case t.String != nil:
+		return "string"
+	case t.Timestamp != nil:
+		return "timestamp"
+	case t.TimestampTz != nil:
+		return "timestamptz"
+	case t.Interval != nil:
+		return "interval"
+	case t.Integer != nil:
+		return "integer"
+	case t.Float != nil:
+		return "float"
+	case t.BitFlag != nil:
+		return "bitflag"
+	case t.Boolean != nil:
+		return "boolean"
+	case t.Json != nil:
+		return "json"
+	}
+
+	return "unknown"
+}
+
+// Returns the name of a column type, e.g. string or []integer for arrays
+func (c CanonicalColumnType) Name() string {
+	inner, array := c.Inner()
+
+	if array {
+		return "[]" + inner.Name()
+	}
+
+	return inner.Name()
+}
+
+// Returns whether a column is ignored for an operation
+func (c CanonicalColumn) IsIgnoredFor(op CanonicalOperationType) bool {
+	return slices.Contains(c.IgnoredFor, op)
+}
+
+// Returns the column with the given ID, if any
+func (c *CanonicalConfigOption) Column(id string) (*CanonicalColumn, bool) {
+	for i := range c.Columns {
+		if c.Columns[i].ID == id {
+			return &c.Columns[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Returns the filters that are applied to an operation: its common filters, or the default common filters if
+// it has none
+func (c *CanonicalConfigOption) FiltersFor(op CanonicalOperationType) *orderedmap.OrderedMap[string, string] {
+	filters := orderedmap.New[string, string]()
+
+	common, ok := c.CommonFilters.Get(op)
+
+	if !ok {
+		common = c.DefaultCommonFilters
+	}
+
+	for pair := common.Oldest(); pair != nil; pair = pair.Next() {
+		filters.Set(pair.Key, pair.Value)
+	}
+
+	return filters
+}
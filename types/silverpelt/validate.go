@@ -0,0 +1,296 @@
+package silverpelt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// The src of the MissingOrInvalidField errors returned by ValidateFields
+const ValidationSrc = "client_validation"
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// Values such as {__guild_id} are filled in by the server, so cannot be checked
+	templateRegex = regexp.MustCompile(`^\{__[a-zA-Z0-9_]+\}$`)
+)
+
+// The layouts accepted for timestamps without a time zone. Timestamps with a time zone must be RFC 3339
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// Returns whether s is a valid value for a Uuid column
+func IsUuid(s string) bool {
+	return uuidRegex.MatchString(s)
+}
+
+// Returns the config option of a setting of a module
+func FindConfigOption(modules []*CanonicalModule, module, setting string) (*CanonicalConfigOption, error) {
+	for _, m := range modules {
+		if m.ID != module {
+			continue
+		}
+
+		for i := range m.ConfigOptions {
+			if m.ConfigOptions[i].ID == setting {
+				return &m.ConfigOptions[i], nil
+			}
+		}
+
+		return nil, fmt.Errorf("module %s has no setting %s", module, setting)
+	}
+
+	return nil, fmt.Errorf("module %s not found", module)
+}
+
+// ValidateFields checks the fields of an operation against the columns of the config option before it is sent,
+// returning the first error the server would return for them, or nil if they are valid
+//
+// Only the fields that are given are checked for views and updates, as they are filters and partial updates
+// respectively. Creates must give every column that is not nullable, ignored or set by the server, and updates
+// and deletes must give the primary key
+func (c *CanonicalConfigOption) ValidateFields(op CanonicalOperationType, fields *orderedmap.OrderedMap[string, any]) *CanonicalSettingsError {
+	if c.Operations.Len() > 0 {
+		if _, ok := c.Operations.Get(op); !ok {
+			return &CanonicalSettingsError{OperationNotSupported: &struct {
+				Operation CanonicalOperationType `json:"operation"`
+			}{Operation: op}}
+		}
+	}
+
+	if op == Update || op == Delete {
+		if v, ok := fields.Get(c.PrimaryKey); !ok || v == nil {
+			return missingField(c.PrimaryKey)
+		}
+	}
+
+	var toSet orderedmap.OrderedMap[string, string]
+
+	if specific, ok := c.Operations.Get(op); ok {
+		toSet = specific.ColumnsToSet
+	}
+
+	for i := range c.Columns {
+		column := &c.Columns[i]
+
+		// The server does not use ignored columns or those it sets itself, except for the primary key identifying
+		// the row of updates and deletes
+		identifies := column.ID == c.PrimaryKey && (op == Update || op == Delete)
+
+		if _, ok := toSet.Get(column.ID); ok || (column.IsIgnoredFor(op) && !identifies) {
+			continue
+		}
+
+		v, ok := fields.Get(column.ID)
+
+		if !ok {
+			if op == Create && !column.Nullable {
+				return missingField(column.ID)
+			}
+
+			continue
+		}
+
+		if err := column.ValidateValue(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func missingField(field string) *CanonicalSettingsError {
+	return &CanonicalSettingsError{MissingOrInvalidField: &struct {
+		Field string `json:"field"`
+		Src   string `json:"src"`
+	}{Field: field, Src: ValidationSrc}}
+}
+
+// ValidateValue checks a value of the column, returning the error the server would return for it, or nil if it
+// is valid
+func (c *CanonicalColumn) ValidateValue(v any) *CanonicalSettingsError {
+	if v == nil {
+		if c.Nullable {
+			return nil
+		}
+
+		return &CanonicalSettingsError{SchemaNullValueValidationError: &struct {
+			Column string `json:"column"`
+		}{Column: c.ID}}
+	}
+
+	if s, ok := v.(string); ok && templateRegex.MatchString(s) {
+		return nil
+	}
+
+	inner, array := c.ColumnType.Inner()
+
+	if !array {
+		// JSON columns take any value, including arrays
+		if isArray(v) && inner.Json == nil {
+			return c.typeError(valueTypeName(v))
+		}
+
+		return c.validateScalar(inner, v)
+	}
+
+	if !isArray(v) {
+		return c.typeError(valueTypeName(v))
+	}
+
+	items := reflect.ValueOf(v)
+
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i).Interface()
+
+		if item == nil {
+			return c.typeError("array containing null")
+		}
+
+		if err := c.validateScalar(inner, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *CanonicalColumn) validateScalar(t CanonicalInnerColumnType, v any) *CanonicalSettingsError {
+	got := valueTypeName(v)
+
+	switch {
+	case t.Uuid != nil:
+		if s, ok := v.(string); !ok || !IsUuid(s) {
+			return c.typeError(got)
+		}
+	case t.String != nil:
+		s, ok := v.(string)
+
+		if !ok {
+			return c.typeError(got)
+		}
+
+		length := utf8.RuneCountInString(s)
+
+		if t.String.MinLength != nil && length < *t.String.MinLength {
+			return c.checkError("minlength", fmt.Sprintf("length %d is less than %d", length, *t.String.MinLength), fmt.Sprintf(">=%d", *t.String.MinLength))
+		}
+
+		if t.String.MaxLength != nil && length > *t.String.MaxLength {
+			return c.checkError("maxlength", fmt.Sprintf("length %d is greater than %d", length, *t.String.MaxLength), fmt.Sprintf("<=%d", *t.String.MaxLength))
+		}
+
+		if len(t.String.AllowedValues) > 0 && !slices.Contains(t.String.AllowedValues, s) {
+			return c.checkError("allowed_values", fmt.Sprintf("%q is not an allowed value", s), strings.Join(t.String.AllowedValues, ", "))
+		}
+	case t.Timestamp != nil || t.TimestampTz != nil:
+		s, ok := v.(string)
+
+		if !ok || !IsTimestamp(s, t.TimestampTz != nil) {
+			return c.typeError(got)
+		}
+	case t.Interval != nil || t.Integer != nil || t.BitFlag != nil:
+		if got != "integer" {
+			return c.typeError(got)
+		}
+	case t.Float != nil:
+		if got != "integer" && got != "float" {
+			return c.typeError(got)
+		}
+	case t.Boolean != nil:
+		if got != "boolean" {
+			return c.typeError(got)
+		}
+	}
+
+	return nil
+}
+
+func (c *CanonicalColumn) typeError(got string) *CanonicalSettingsError {
+	return &CanonicalSettingsError{SchemaTypeValidationError: &struct {
+		Column       string `json:"column"`
+		ExpectedType string `json:"expected_type"`
+		GotType      string `json:"got_type"`
+	}{Column: c.ID, ExpectedType: c.ColumnType.Name(), GotType: got}}
+}
+
+func (c *CanonicalColumn) checkError(check, err, acceptedRange string) *CanonicalSettingsError {
+	return &CanonicalSettingsError{SchemaCheckValidationError: &struct {
+		Column        string `json:"column"`
+		Check         string `json:"check"`
+		Error         string `json:"error"`
+		AcceptedRange string `json:"accepted_range"`
+	}{Column: c.ID, Check: check, Error: err, AcceptedRange: acceptedRange}}
+}
+
+// Returns whether s is a valid value for a TimestampTz (withTimeZone) or Timestamp column
+func IsTimestamp(s string, withTimeZone bool) bool {
+	if withTimeZone {
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isArray(v any) bool {
+	kind := reflect.TypeOf(v).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+// Returns the JSON type of a value, with numbers split into integers and floats
+func valueTypeName(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+
+		return "float"
+	case float32:
+		return floatTypeName(float64(v))
+	case float64:
+		return floatTypeName(v)
+	}
+
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return reflect.TypeOf(v).String()
+}
+
+// Whole floats are integers, as JSON numbers decoded without UseNumber are always float64
+func floatTypeName(f float64) string {
+	if f == math.Trunc(f) && !math.IsInf(f, 0) {
+		return "integer"
+	}
+
+	return "float"
+}
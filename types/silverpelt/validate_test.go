@@ -0,0 +1,92 @@
+package silverpelt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const validateOption = `{
+	"id": "sinks",
+	"primary_key": "id",
+	"columns": [
+		{"id": "id", "column_type": {"Scalar": {"column_type": {"Uuid": {}}}}, "ignored_for": ["Create"]},
+		{"id": "guild_id", "column_type": {"Scalar": {"column_type": {"String": {}}}}, "ignored_for": ["View", "Create", "Update"]},
+		{"id": "type", "column_type": {"Scalar": {"column_type": {"String": {"allowed_values": ["channel", "discordhook"]}}}}},
+		{"id": "sink", "column_type": {"Scalar": {"column_type": {"String": {"min_length": 1, "max_length": 4}}}}},
+		{"id": "events", "nullable": true, "column_type": {"Array": {"inner": {"String": {}}}}},
+		{"id": "count", "nullable": true, "column_type": {"Scalar": {"column_type": {"Integer": {}}}}},
+		{"id": "data", "nullable": true, "column_type": {"Scalar": {"column_type": {"Json": {}}}}},
+		{"id": "created_by", "column_type": {"Scalar": {"column_type": {"String": {}}}}}
+	],
+	"operations": {
+		"View": {},
+		"Create": {"columns_to_set": {"created_by": "{__author}"}},
+		"Update": {},
+		"Delete": {}
+	}
+}`
+
+func fieldsOf(kv ...any) *orderedmap.OrderedMap[string, any] {
+	fields := orderedmap.New[string, any]()
+	for i := 0; i < len(kv); i += 2 {
+		fields.Set(kv[i].(string), kv[i+1])
+	}
+	return fields
+}
+
+func TestValidateFields(t *testing.T) {
+	var opt CanonicalConfigOption
+	assert.NoError(t, json.Unmarshal([]byte(validateOption), &opt))
+
+	const id = "0f8fad5b-d9cb-469f-a165-70867728950e"
+
+	assert.Nil(t, opt.ValidateFields(Create, fieldsOf("type", "channel", "sink", "123", "events", []any{"a"}, "count", int64(3), "data", []any{1})))
+
+	// Columns set by the server or ignored are not required, but other columns that are not nullable are
+	err := opt.ValidateFields(Create, fieldsOf("type", "channel"))
+	assert.Equal(t, "sink", err.MissingOrInvalidField.Field)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", nil, "sink", "1"))
+	assert.Equal(t, "type", err.SchemaNullValueValidationError.Column)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", "other", "sink", "1"))
+	assert.Equal(t, "allowed_values", err.SchemaCheckValidationError.Check)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", "channel", "sink", "12345"))
+	assert.Equal(t, "maxlength", err.SchemaCheckValidationError.Check)
+	assert.Equal(t, "<=4", err.SchemaCheckValidationError.AcceptedRange)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", "channel", "sink", ""))
+	assert.Equal(t, "minlength", err.SchemaCheckValidationError.Check)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", "channel", "sink", "1", "events", "a"))
+	assert.Equal(t, "[]string", err.SchemaTypeValidationError.ExpectedType)
+	assert.Equal(t, "string", err.SchemaTypeValidationError.GotType)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", "channel", "sink", "1", "count", []any{1}))
+	assert.Equal(t, "integer", err.SchemaTypeValidationError.ExpectedType)
+	assert.Equal(t, "array", err.SchemaTypeValidationError.GotType)
+
+	err = opt.ValidateFields(Create, fieldsOf("type", "channel", "sink", "1", "count", 1.5))
+	assert.Equal(t, "float", err.SchemaTypeValidationError.GotType)
+
+	// Updates may only give some columns, but must give the primary key
+	assert.Nil(t, opt.ValidateFields(Update, fieldsOf("id", id, "sink", "1")))
+
+	err = opt.ValidateFields(Update, fieldsOf("sink", "1"))
+	assert.Equal(t, "id", err.MissingOrInvalidField.Field)
+
+	err = opt.ValidateFields(Delete, fieldsOf("id", "123"))
+	assert.Equal(t, "uuid", err.SchemaTypeValidationError.ExpectedType)
+
+	// Values filled in by the server are not checked
+	assert.Nil(t, opt.ValidateFields(View, fieldsOf("guild_id", "{__guild_id}", "count", "{__count}")))
+
+	opt.Operations.Delete(Delete)
+
+	err = opt.ValidateFields(Delete, fieldsOf("id", id))
+	assert.Equal(t, Delete, err.OperationNotSupported.Operation)
+}